@@ -0,0 +1,120 @@
+// This file contains the account policies check. Account policies created with PutAccountPolicy apply subscription
+// filters, data protection, field indexes or transformers to the log groups their selection criteria select,
+// without any trace in the state of the log groups themselves.
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// Types of account policies that apply a Standard-only feature, with the name they are reported by
+var accountPolicyTypes = []struct {
+	policyType types.PolicyType
+	label      string
+}{
+	{types.PolicyTypeSubscriptionFilterPolicy, "subscription filter"},
+	{types.PolicyTypeDataProtectionPolicy, "data protection"},
+	{types.PolicyTypeFieldIndexPolicy, "field index"},
+	{types.PolicyTypeTransformerPolicy, "transformer"},
+}
+
+// Selection criteria of account policies, e.g. LogGroupName NOT IN ["log1", "log2"] or LogGroupNamePrefix IN ["/aws/"]
+var selectionCriteriaPattern = regexp.MustCompile(`(?s)^\s*(LogGroupName|LogGroupNamePrefix)\s+(NOT\s+)?IN\s*(\[.*\])\s*$`)
+
+// accountPolicy is an account policy with its parsed selection criteria
+type accountPolicy struct {
+	Name  string
+	Label string
+	// Nil when the policy applies to every log group
+	Criteria *selectionCriteria
+}
+
+// selectionCriteria selects log groups by name or name prefix
+type selectionCriteria struct {
+	Prefix bool
+	Not    bool
+	Values []string
+}
+
+// Parse the selection criteria of an account policy. Empty criteria select every log group and return nil.
+func parseSelectionCriteria(criteria string) (*selectionCriteria, error) {
+	if strings.TrimSpace(criteria) == "" {
+		return nil, nil
+	}
+	match := selectionCriteriaPattern.FindStringSubmatch(criteria)
+	if match == nil {
+		return nil, fmt.Errorf("unsupported selection criteria %q", criteria)
+	}
+	parsed := &selectionCriteria{Prefix: match[1] == "LogGroupNamePrefix", Not: match[2] != ""}
+	if err := json.Unmarshal([]byte(match[3]), &parsed.Values); err != nil {
+		return nil, fmt.Errorf("unsupported selection criteria %q: %v", criteria, err)
+	}
+	return parsed, nil
+}
+
+// Return true if the criteria select the log group
+func (c *selectionCriteria) matches(logGroupName string) bool {
+	if c == nil {
+		return true
+	}
+	in := false
+	for _, value := range c.Values {
+		if logGroupName == value || (c.Prefix && strings.HasPrefix(logGroupName, value)) {
+			in = true
+			break
+		}
+	}
+	return in != c.Not
+}
+
+// Return the account policies of every type that applies a Standard-only feature. A policy with selection criteria
+// that cannot be evaluated is an error, as the log groups it covers are unknown.
+func listAccountPolicies(ctx context.Context, client CloudWatchLogsClient) ([]accountPolicy, error) {
+	var policies []accountPolicy
+	for _, policyType := range accountPolicyTypes {
+		input := &cloudwatchlogs.DescribeAccountPoliciesInput{PolicyType: policyType.policyType}
+		for {
+			resp, err := client.DescribeAccountPolicies(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("describing %s account policies: %w", policyType.label, err)
+			}
+			for _, policy := range resp.AccountPolicies {
+				criteria, err := parseSelectionCriteria(aws.ToString(policy.SelectionCriteria))
+				if err != nil {
+					return nil, fmt.Errorf("%s account policy %s: %w", policyType.label, aws.ToString(policy.PolicyName), err)
+				}
+				policies = append(policies, accountPolicy{Name: aws.ToString(policy.PolicyName), Label: policyType.label, Criteria: criteria})
+			}
+			if resp.NextToken == nil {
+				break
+			}
+			input.NextToken = resp.NextToken
+		}
+	}
+	return policies, nil
+}
+
+// Account policy check. Returns a finding for the log groups selected by an account policy.
+func accountPolicyFindings(verdicts []*Verdict, policies []accountPolicy) []Finding {
+	var findings []Finding
+	for _, verdict := range verdicts {
+		var covering []string
+		for _, policy := range policies {
+			if policy.Criteria.matches(verdict.LogGroupName) {
+				covering = append(covering, fmt.Sprintf("%s (%s)", policy.Name, policy.Label))
+			}
+		}
+		if len(covering) > 0 {
+			findings = append(findings, Finding{LogGroupName: verdict.LogGroupName, Detail: "account policies: " + strings.Join(covering, ", ")})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,101 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func TestSelectionCriteriaMatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		criteria    string
+		logGroup    string
+		expected    bool
+		expectedErr bool
+	}{
+		{name: "No criteria", criteria: "", logGroup: "log1", expected: true},
+		{name: "Name not in list", criteria: `LogGroupName NOT IN ["log1", "log2"]`, logGroup: "log3", expected: true},
+		{name: "Name in NOT IN list", criteria: `LogGroupName NOT IN ["log1", "log2"]`, logGroup: "log2", expected: false},
+		{name: "Empty NOT IN list", criteria: `LogGroupName NOT IN []`, logGroup: "log1", expected: true},
+		{name: "Prefix match", criteria: `LogGroupNamePrefix IN ["/aws/lambda/", "/ecs/"]`, logGroup: "/ecs/web", expected: true},
+		{name: "Prefix mismatch", criteria: `LogGroupNamePrefix IN ["/aws/lambda/"]`, logGroup: "/ecs/web", expected: false},
+		{name: "Name is not a prefix", criteria: `LogGroupName IN ["/ecs"]`, logGroup: "/ecs/web", expected: false},
+		{name: "Unsupported field", criteria: `LogGroupClass IN ["STANDARD"]`, expectedErr: true},
+		{name: "Invalid list", criteria: `LogGroupName NOT IN [log1]`, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			criteria, err := parseSelectionCriteria(tt.criteria)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("parseSelectionCriteria(%q) expected an error", tt.criteria)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSelectionCriteria(%q) unexpected error: %v", tt.criteria, err)
+			}
+			if matches := criteria.matches(tt.logGroup); matches != tt.expected {
+				t.Errorf("matches(%q) = %v, want %v", tt.logGroup, matches, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAccountPolicyCheck(t *testing.T) {
+	client := &mockCloudWatchLogsClient{
+		accountPolicies: []types.AccountPolicy{
+			{PolicyName: aws.String("to-firehose"), PolicyType: types.PolicyTypeSubscriptionFilterPolicy, SelectionCriteria: aws.String(`LogGroupName NOT IN ["firehose-errors"]`)},
+			{PolicyName: aws.String("pii"), PolicyType: types.PolicyTypeDataProtectionPolicy},
+			{PolicyName: aws.String("lambda-index"), PolicyType: types.PolicyTypeFieldIndexPolicy, SelectionCriteria: aws.String(`LogGroupNamePrefix IN ["/aws/lambda/"]`)},
+			{PolicyName: aws.String("parse-json"), PolicyType: types.PolicyTypeTransformerPolicy, SelectionCriteria: aws.String(`LogGroupNamePrefix IN ["/ecs/"]`)},
+		},
+	}
+	env := &CheckEnv{Region: "us-west-2", Rules: DefaultRules(), Logs: client}
+
+	verdicts := verdictsFromNames("/aws/lambda/app", "/ecs/web", "firehose-errors")
+	runChecksOnBatch(context.Background(), env, checksNamed(CheckAccountPolicy), verdicts)
+
+	expected := map[string][]Reason{
+		"/aws/lambda/app": {{Check: CheckAccountPolicy, Detail: "account policies: to-firehose (subscription filter), pii (data protection), lambda-index (field index)"}},
+		"/ecs/web":        {{Check: CheckAccountPolicy, Detail: "account policies: to-firehose (subscription filter), pii (data protection), parse-json (transformer)"}},
+		"firehose-errors": {{Check: CheckAccountPolicy, Detail: "account policies: pii (data protection)"}},
+	}
+	for _, v := range verdicts {
+		if !reflect.DeepEqual(v.Reasons, expected[v.LogGroupName]) {
+			t.Errorf("%s reasons = %v, want %v", v.LogGroupName, v.Reasons, expected[v.LogGroupName])
+		}
+	}
+}
+
+func TestAccountPolicyCheckErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *mockCloudWatchLogsClient
+	}{
+		{name: "DescribeAccountPolicies error", client: &mockCloudWatchLogsClient{describeAccountPoliciesErr: errors.New("AccessDeniedException")}},
+		{
+			name: "Unsupported selection criteria",
+			client: &mockCloudWatchLogsClient{accountPolicies: []types.AccountPolicy{
+				{PolicyName: aws.String("future"), PolicyType: types.PolicyTypeSubscriptionFilterPolicy, SelectionCriteria: aws.String(`LogGroupClass = "STANDARD"`)},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &CheckEnv{Region: "us-west-2", Rules: DefaultRules(), Logs: tt.client}
+			verdicts := verdictsFromNames("log1")
+			runChecksOnBatch(context.Background(), env, checksNamed(CheckAccountPolicy), verdicts)
+			if status := verdicts[0].Status(); status != StatusUnknown {
+				t.Errorf("status = %v, want %v", status, StatusUnknown)
+			}
+		})
+	}
+}
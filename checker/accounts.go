@@ -0,0 +1,197 @@
+// This file contains the helpers for scanning many accounts by assuming a role in each of them.
+package checker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// OrganizationsClient is an interface for AWS Organizations operations
+type OrganizationsClient interface {
+	ListAccounts(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error)
+}
+
+// Number of accounts that are scanned at the same time unless -account-concurrency is set
+const DefaultAccountConcurrency = 4
+
+// Session name used when assuming the role in each account
+const roleSessionName = "log-ia-checker"
+
+var accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+
+// ScanFailure records an account (or region of an account) that could not be scanned
+type ScanFailure struct {
+	Account string `json:"account"`
+	Region  string `json:"region,omitempty"`
+	Error   string `json:"error"`
+}
+
+// Return the IDs of the active accounts in the organization
+func ListOrganizationAccounts(ctx context.Context, client OrganizationsClient) ([]string, error) {
+	var accounts []string
+
+	paginator := organizations.NewListAccountsPaginator(client, &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range page.Accounts {
+			if account.Status == orgtypes.AccountStatusActive {
+				accounts = append(accounts, aws.ToString(account.Id))
+			}
+		}
+	}
+
+	return accounts, nil
+}
+
+// Read account IDs from a file, one per line. Blank lines and lines starting with # are ignored.
+func ReadAccountsFile(fileName string) ([]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var accounts []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !accountIDPattern.MatchString(line) {
+			return nil, fmt.Errorf("%s:%d: %q is not a 12 digit account ID", fileName, lineNum, line)
+		}
+		if !seen[line] {
+			seen[line] = true
+			accounts = append(accounts, line)
+		}
+	}
+
+	return accounts, scanner.Err()
+}
+
+// Return the partition of a region, e.g. aws-cn for cn-north-1
+func partitionForRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	default:
+		return "aws"
+	}
+}
+
+// Return the ARN of the role to assume in an account
+func roleArn(partition, accountID, roleName string) string {
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, accountID, roleName)
+}
+
+// Scan every account by assuming roleName in it, with at most concurrency accounts at a time.
+// A failure in one account is recorded and does not stop the others.
+func ScanAccounts(ctx context.Context, cfg aws.Config, accounts []string, roleName string, concurrency int, regions []string, options Options) ([]*Verdict, []ScanFailure) {
+	results := make([][]*Verdict, len(accounts))
+	failures := scanAccounts(ctx, cfg, accounts, roleName, concurrency, func(index int, accountCfg aws.Config) []ScanFailure {
+		var regionFailures []ScanFailure
+		accountOptions := options
+		accountOptions.Account = accounts[index]
+		results[index], regionFailures = ScanRegions(ctx, accountCfg, regions, accountOptions)
+		return regionFailures
+	})
+
+	var verdicts []*Verdict
+	for _, result := range results {
+		verdicts = append(verdicts, result...)
+	}
+	return verdicts, failures
+}
+
+// StreamAccounts is ScanAccounts for runs too large to hold every verdict in memory. The verdicts are only passed
+// to options.OnVerdict as soon as they are done, which the accounts and regions call concurrently, and none are kept.
+func StreamAccounts(ctx context.Context, cfg aws.Config, accounts []string, roleName string, concurrency int, regions []string, options Options) []ScanFailure {
+	return scanAccounts(ctx, cfg, accounts, roleName, concurrency, func(index int, accountCfg aws.Config) []ScanFailure {
+		accountOptions := options
+		accountOptions.Account = accounts[index]
+		return StreamRegions(ctx, accountCfg, regions, accountOptions)
+	})
+}
+
+// Assume roleName in every account, with at most concurrency accounts at a time, and scan it with the credentials
+// of the role. Returns the accounts that could not be scanned and the regions scan failed for.
+func scanAccounts(ctx context.Context, cfg aws.Config, accounts []string, roleName string, concurrency int, scan func(index int, accountCfg aws.Config) []ScanFailure) []ScanFailure {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	regionFailures := make([][]ScanFailure, len(accounts))
+	failures := make([]*ScanFailure, len(accounts))
+	stsClient := sts.NewFromConfig(cfg)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, accountID := range accounts {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(index int, accountID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// Isolate the account, a panic in one scan must not take down the whole run
+			defer func() {
+				if r := recover(); r != nil {
+					failures[index] = &ScanFailure{Account: accountID, Error: fmt.Sprintf("scan panicked: %v", r)}
+				}
+			}()
+
+			if err := ctx.Err(); err != nil {
+				failures[index] = &ScanFailure{Account: accountID, Error: fmt.Sprintf("not scanned: %v", err)}
+				return
+			}
+
+			accountCfg := cfg.Copy()
+			arn := roleArn(partitionForRegion(cfg.Region), accountID, roleName)
+			accountCfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(stsClient, arn, func(o *stscreds.AssumeRoleOptions) {
+				o.RoleSessionName = roleSessionName
+			}))
+
+			// Assume the role up front so a missing role is reported once instead of failing every call
+			if _, err := accountCfg.Credentials.Retrieve(ctx); err != nil {
+				failures[index] = &ScanFailure{Account: accountID, Error: fmt.Sprintf("unable to assume %s: %v", arn, err)}
+				return
+			}
+
+			log.Printf("[%s] Scanning account", accountID)
+			regionFailures[index] = scan(index, accountCfg)
+		}(i, accountID)
+	}
+	wg.Wait()
+
+	var failed []ScanFailure
+	for i := range accounts {
+		if failures[i] != nil {
+			log.Printf("[%s] Account could not be scanned: %s", failures[i].Account, failures[i].Error)
+			failed = append(failed, *failures[i])
+		}
+		for _, failure := range regionFailures[i] {
+			failure.Account = accounts[i]
+			failed = append(failed, failure)
+		}
+	}
+	return failed
+}
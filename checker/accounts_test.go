@@ -0,0 +1,98 @@
+package checker
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+)
+
+// Mock Organizations client for testing
+type mockOrganizationsClient struct {
+	listAccountsOutput *organizations.ListAccountsOutput
+	listAccountsErr    error
+}
+
+func (m *mockOrganizationsClient) ListAccounts(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error) {
+	return m.listAccountsOutput, m.listAccountsErr
+}
+
+func TestListOrganizationAccounts(t *testing.T) {
+	mockClient := &mockOrganizationsClient{
+		listAccountsOutput: &organizations.ListAccountsOutput{
+			Accounts: []orgtypes.Account{
+				{Id: aws.String("111111111111"), Status: orgtypes.AccountStatusActive},
+				{Id: aws.String("222222222222"), Status: orgtypes.AccountStatusSuspended},
+				{Id: aws.String("333333333333"), Status: orgtypes.AccountStatusActive},
+			},
+		},
+	}
+
+	accounts, err := ListOrganizationAccounts(context.Background(), mockClient)
+	if err != nil {
+		t.Fatalf("ListOrganizationAccounts() error = %v", err)
+	}
+	if !reflect.DeepEqual(accounts, []string{"111111111111", "333333333333"}) {
+		t.Errorf("ListOrganizationAccounts() = %v, want only the active accounts", accounts)
+	}
+}
+
+func TestReadAccountsFile(t *testing.T) {
+	tempFile := "test_accounts.txt"
+	defer os.Remove(tempFile)
+
+	tests := []struct {
+		name        string
+		content     string
+		expected    []string
+		expectError bool
+	}{
+		{
+			name:     "Accounts with comments and blank lines",
+			content:  "# production\n111111111111\n\n  222222222222  \n111111111111\n",
+			expected: []string{"111111111111", "222222222222"},
+		},
+		{
+			name:        "Invalid account ID",
+			content:     "111111111111\nnot-an-account\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(tempFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write accounts file: %v", err)
+			}
+
+			accounts, err := ReadAccountsFile(tempFile)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ReadAccountsFile() error = %v, expectError %v", err, tt.expectError)
+			}
+			if !tt.expectError && !reflect.DeepEqual(accounts, tt.expected) {
+				t.Errorf("ReadAccountsFile() = %v, want %v", accounts, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRoleArn(t *testing.T) {
+	tests := []struct {
+		region   string
+		expected string
+	}{
+		{"us-east-1", "arn:aws:iam::111111111111:role/Auditor"},
+		{"cn-north-1", "arn:aws-cn:iam::111111111111:role/Auditor"},
+		{"us-gov-west-1", "arn:aws-us-gov:iam::111111111111:role/Auditor"},
+	}
+
+	for _, tt := range tests {
+		if result := roleArn(partitionForRegion(tt.region), "111111111111", "Auditor"); result != tt.expected {
+			t.Errorf("roleArn() for %s = %v, want %v", tt.region, result, tt.expected)
+		}
+	}
+}
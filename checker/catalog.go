@@ -0,0 +1,186 @@
+// This file contains the catalog of log groups that AWS services create and read themselves. Their names follow
+// patterns set by the services rather than by the teams, and each service has its own reason to need the Standard
+// class or to be looked at before switching.
+package checker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Version of the built-in catalog, bumped whenever its patterns change
+const builtinCatalogVersion = "2025.07"
+
+// ManagedPattern matches the log groups of an AWS service by name prefix or regular expression
+type ManagedPattern struct {
+	// Service that creates the log groups, e.g. "Lambda Insights"
+	Service string `json:"service"`
+	// Prefix of the log group names, set either Prefix or Regex
+	Prefix string `json:"prefix,omitempty"`
+	// Regular expression matched against the whole log group name
+	Regex string `json:"regex,omitempty"`
+	// Why the log groups must stay Standard or need special handling
+	Reason string `json:"reason"`
+	// Informational patterns are reported on the log group without making it ineligible
+	Informational bool `json:"informational,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+// Catalog is a versioned list of the AWS-managed log group patterns the insights check looks for.
+// The version is recorded in the report metadata.
+type Catalog struct {
+	Version  string           `json:"version"`
+	Patterns []ManagedPattern `json:"patterns"`
+}
+
+// Built-in patterns of AWS-managed log groups
+var builtinManagedPatterns = []ManagedPattern{
+	{
+		Service: "Lambda Insights",
+		Prefix:  "/aws/lambda-insights",
+		Reason:  "performance events in the Embedded Metric Format, whose metrics IA does not extract",
+	},
+	{
+		// Only the performance log group, the application, host and dataplane ones hold the container and node logs
+		Service: "Container Insights",
+		Regex:   `/aws/containerinsights/[^/]+/performance`,
+		Reason:  "performance events in the Embedded Metric Format, whose metrics IA does not extract",
+	},
+	{
+		Service: "ECS Container Insights",
+		Regex:   `/aws/ecs/containerinsights/[^/]+/performance`,
+		Reason:  "performance events in the Embedded Metric Format, whose metrics IA does not extract",
+	},
+	{
+		Service: "Application Signals",
+		Prefix:  "/aws/application-signals/",
+		Reason:  "service metrics in the Embedded Metric Format, whose metrics IA does not extract",
+	},
+	{
+		Service: "X-Ray Transaction Search",
+		Regex:   `aws/spans`,
+		Reason:  "spans X-Ray indexes into trace summaries and span metrics from the log group",
+	},
+	{
+		Service:       "CloudWatch RUM",
+		Prefix:        "/aws/vendedlogs/RUMService_",
+		Reason:        "copy of the app monitor events, check the dashboards and queries built on it before switching",
+		Informational: true,
+	},
+}
+
+// Return the built-in catalog
+func DefaultCatalog() *Catalog {
+	catalog := &Catalog{Version: builtinCatalogVersion, Patterns: append([]ManagedPattern{}, builtinManagedPatterns...)}
+	if err := catalog.compile(); err != nil {
+		panic(err)
+	}
+	return catalog
+}
+
+// Load the catalog, extending the built-in patterns with those of a JSON file of the form
+// {"version": "team-1", "patterns": [{"service": "...", "prefix": "...", "reason": "..."}]}. The patterns of the
+// file are matched first, so they can also change how a built-in pattern is handled. An empty file name returns the
+// built-in catalog.
+func LoadCatalog(fileName string) (*Catalog, error) {
+	if fileName == "" {
+		return DefaultCatalog(), nil
+	}
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	extension := &Catalog{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(extension); err != nil {
+		return nil, fmt.Errorf("parsing catalog file %s: %w", fileName, err)
+	}
+	if extension.Version == "" {
+		return nil, fmt.Errorf("catalog file %s has no version", fileName)
+	}
+
+	catalog := &Catalog{
+		Version:  builtinCatalogVersion + "+" + extension.Version,
+		Patterns: append(extension.Patterns, builtinManagedPatterns...),
+	}
+	if err := catalog.compile(); err != nil {
+		return nil, fmt.Errorf("catalog file %s: %w", fileName, err)
+	}
+	return catalog, nil
+}
+
+// Validate the patterns and compile their regular expressions
+func (c *Catalog) compile() error {
+	for i := range c.Patterns {
+		pattern := &c.Patterns[i]
+		switch {
+		case pattern.Service == "":
+			return fmt.Errorf("patterns[%d] has no service", i)
+		case pattern.Reason == "":
+			return fmt.Errorf("patterns[%d] has no reason", i)
+		case (pattern.Prefix == "") == (pattern.Regex == ""):
+			return fmt.Errorf("patterns[%d] must have either a prefix or a regex", i)
+		}
+		if pattern.Regex != "" {
+			regex, err := regexp.Compile("^(?:" + pattern.Regex + ")$")
+			if err != nil {
+				return fmt.Errorf("patterns[%d]: invalid regex %q: %v", i, pattern.Regex, err)
+			}
+			pattern.regex = regex
+		}
+	}
+	return nil
+}
+
+// Return the first pattern matching the log group name, or nil if it is not a known AWS-managed log group.
+// A nil catalog is the built-in one.
+func (c *Catalog) match(logGroupName string) *ManagedPattern {
+	if c == nil {
+		c = defaultCatalog
+	}
+	for i := range c.Patterns {
+		pattern := &c.Patterns[i]
+		if pattern.regex != nil {
+			if pattern.regex.MatchString(logGroupName) {
+				return pattern
+			}
+		} else if strings.HasPrefix(logGroupName, pattern.Prefix) {
+			return pattern
+		}
+	}
+	return nil
+}
+
+// Built-in catalog of the checks run without one
+var defaultCatalog = DefaultCatalog()
+
+// Insights check. Returns a finding for the AWS-managed log groups of the catalog, and for the log groups whose name
+// contains one of the insightsPatterns of the rules.
+func managedLogGroupFindings(verdicts []*Verdict, catalog *Catalog, rules *Rules) []Finding {
+	var findings []Finding
+	for _, verdict := range verdicts {
+		if pattern := catalog.match(verdict.LogGroupName); pattern != nil {
+			findings = append(findings, Finding{
+				LogGroupName:  verdict.LogGroupName,
+				Detail:        fmt.Sprintf("%s log group: %s", pattern.Service, pattern.Reason),
+				Informational: pattern.Informational,
+			})
+			continue
+		}
+		for _, substring := range rules.InsightsPatterns {
+			if strings.Contains(verdict.LogGroupName, substring) {
+				findings = append(findings, Finding{LogGroupName: verdict.LogGroupName, Detail: "name contains insights pattern " + substring})
+				break
+			}
+		}
+	}
+	return findings
+}
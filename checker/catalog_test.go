@@ -0,0 +1,109 @@
+package checker
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestCatalogMatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		logGroupName string
+		expected     string
+	}{
+		{name: "Lambda Insights", logGroupName: "/aws/lambda-insights", expected: "Lambda Insights"},
+		{name: "Container Insights", logGroupName: "/aws/containerinsights/my-cluster/performance", expected: "Container Insights"},
+		{name: "Container Insights application logs", logGroupName: "/aws/containerinsights/my-cluster/application", expected: ""},
+		{name: "Container Insights host logs", logGroupName: "/aws/containerinsights/my-cluster/host", expected: ""},
+		{name: "ECS Container Insights", logGroupName: "/aws/ecs/containerinsights/my-cluster/performance", expected: "ECS Container Insights"},
+		{name: "Application Signals", logGroupName: "/aws/application-signals/data", expected: "Application Signals"},
+		{name: "X-Ray spans", logGroupName: "aws/spans", expected: "X-Ray Transaction Search"},
+		{name: "CloudWatch RUM", logGroupName: "/aws/vendedlogs/RUMService_my-app1a2b3c4d", expected: "CloudWatch RUM"},
+		{name: "Regexes match the whole name", logGroupName: "/team/aws/spans", expected: ""},
+		{name: "User log group named after insights", logGroupName: "my-containerinsights-copy", expected: ""},
+		{name: "Regular log group", logGroupName: "/aws/lambda/my-function", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var service string
+			if pattern := DefaultCatalog().match(tt.logGroupName); pattern != nil {
+				service = pattern.Service
+			}
+			if service != tt.expected {
+				t.Errorf("match(%q) = %q, want %q", tt.logGroupName, service, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr bool
+	}{
+		{
+			name:    "Valid catalog",
+			content: `{"version": "team-1", "patterns": [{"service": "Batch", "regex": "/aws/batch/job-[0-9]+", "reason": "dashboards"}, {"service": "RUM", "prefix": "/aws/vendedlogs/RUMService_", "reason": "alarms on the events"}]}`,
+		},
+		{name: "Missing version", content: `{"patterns": []}`, expectedErr: true},
+		{name: "Unknown field", content: `{"version": "1", "pattern": []}`, expectedErr: true},
+		{name: "Missing reason", content: `{"version": "1", "patterns": [{"service": "Batch", "prefix": "/aws/batch/"}]}`, expectedErr: true},
+		{name: "Prefix and regex", content: `{"version": "1", "patterns": [{"service": "Batch", "prefix": "/aws/batch/", "regex": "x", "reason": "r"}]}`, expectedErr: true},
+		{name: "Invalid regex", content: `{"version": "1", "patterns": [{"service": "Batch", "regex": "(", "reason": "r"}]}`, expectedErr: true},
+	}
+
+	tempFile := "test_catalog.json"
+	defer os.Remove(tempFile)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(tempFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write catalog file: %v", err)
+			}
+			catalog, err := LoadCatalog(tempFile)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("LoadCatalog() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadCatalog() unexpected error: %v", err)
+			}
+			if catalog.Version != builtinCatalogVersion+"+team-1" {
+				t.Errorf("Version = %q, want %q", catalog.Version, builtinCatalogVersion+"+team-1")
+			}
+			if pattern := catalog.match("/aws/batch/job-42"); pattern == nil || pattern.Service != "Batch" {
+				t.Errorf("match(/aws/batch/job-42) = %+v, want Batch", pattern)
+			}
+			// The patterns of the file take precedence over the built-in ones
+			if pattern := catalog.match("/aws/vendedlogs/RUMService_app"); pattern == nil || pattern.Informational {
+				t.Errorf("match(/aws/vendedlogs/RUMService_app) = %+v, want the pattern of the file", pattern)
+			}
+			if pattern := catalog.match("/aws/lambda-insights"); pattern == nil {
+				t.Errorf("match(/aws/lambda-insights) = nil, want the built-in pattern")
+			}
+		})
+	}
+}
+
+func TestManagedLogGroupFindings(t *testing.T) {
+	rules := &Rules{InsightsPatterns: []string{"-metrics"}}
+	env := &CheckEnv{Rules: rules}
+	verdicts := verdictsFromNames("/aws/application-signals/data", "/aws/vendedlogs/RUMService_app", "/app/custom-metrics", "/app/orders")
+	runChecksOnBatch(context.Background(), env, checksNamed(CheckInsights), verdicts)
+
+	expected := map[string]string{
+		"/aws/application-signals/data":  "fail: Application Signals log group: service metrics in the Embedded Metric Format, whose metrics IA does not extract",
+		"/aws/vendedlogs/RUMService_app": "pass: CloudWatch RUM log group: copy of the app monitor events, check the dashboards and queries built on it before switching",
+		"/app/custom-metrics":            "fail: name contains insights pattern -metrics",
+		"/app/orders":                    "pass",
+	}
+	for _, v := range verdicts {
+		if result := v.checkResult(CheckInsights); result != expected[v.LogGroupName] {
+			t.Errorf("%s result = %q, want %q", v.LogGroupName, result, expected[v.LogGroupName])
+		}
+	}
+}
@@ -0,0 +1,312 @@
+// This file contains the Check interface, the registry of checks and the engine that runs them against the log groups of a region.
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Check evaluates a batch of log groups and returns a finding for every log group it excludes
+// or could not evaluate. Log groups of the batch without a finding pass the check.
+type Check interface {
+	// Name of the check as used in the reports and the rules file, e.g. "live_tail"
+	Name() string
+	// Run the check against the log groups of a batch that are still in consideration. It is called once per
+	// page of DescribeLogGroups, so data covering the whole account should be loaded with CheckEnv.LoadOnce.
+	// An error means the check could not be run at all and leaves every log group of the batch undetermined.
+	Run(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error)
+}
+
+// Finding is the outcome of a check for a single log group
+type Finding struct {
+	LogGroupName string
+	// Why the check excludes the log group
+	Detail string
+	// Set instead of Detail when the check could not be evaluated for the log group
+	Err error
+	// Set when Detail is only reported and does not exclude the log group
+	Informational bool
+	// Calls of Standard-only APIs on the log group that led to the finding
+	Usages []Usage
+}
+
+// CheckEnv holds what a check needs to evaluate the log groups of a region
+type CheckEnv struct {
+	// Account the clients are for, empty when it is the account of the current credentials
+	Account    string
+	Region     string
+	Now        time.Time
+	Rules      *Rules
+	Logs       CloudWatchLogsClient
+	CloudTrail CloudTrailClient
+	// Worker pool for checks that call an API per log group
+	Engine *Engine
+	// Progress of the scan, nil when it is not reported
+	Progress *Progress
+	// Counts the CloudTrail events that could not be used, nil when they are not counted
+	TrailStats *TrailStats
+	// Directory of CloudTrail log files the CloudTrail checks read instead of calling LookupEvents, when set
+	TrailArchive string
+	// CloudTrail Lake event data store the CloudTrail checks query instead of calling LookupEvents, when set
+	TrailLake *TrailLake
+	// AWS-managed log group patterns of the insights check, the built-in catalog when nil
+	Catalog *Catalog
+
+	// Data loaded once per scan, shared by the checks
+	cache *scanCache
+}
+
+// scanCache holds the values loaded with CheckEnv.LoadOnce
+type scanCache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	once  sync.Once
+	value any
+	err   error
+}
+
+// LoadOnce returns the value loaded for key during the scan, calling load only the first time the key is asked for.
+// The checks run once per batch of log groups, so data that covers the whole account and region, such as the
+// anomaly detectors or CloudTrail events, is loaded through it. A failed load fails every batch the same way.
+func (env *CheckEnv) LoadOnce(key string, load func() (any, error)) (any, error) {
+	if env.cache == nil {
+		return load()
+	}
+	env.cache.mu.Lock()
+	entry, ok := env.cache.entries[key]
+	if !ok {
+		entry = &cacheEntry{}
+		env.cache.entries[key] = entry
+	}
+	env.cache.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.err = callRecovered(func() error {
+			var err error
+			entry.value, err = load()
+			return err
+		})
+	})
+	return entry.value, entry.err
+}
+
+// funcCheck adapts a function to the Check interface
+type funcCheck struct {
+	name string
+	run  func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error)
+}
+
+func (c funcCheck) Name() string { return c.name }
+
+func (c funcCheck) Run(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+	return c.run(ctx, env, batch)
+}
+
+// NewCheck returns a Check with the given name that calls run
+func NewCheck(name string, run func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error)) Check {
+	return funcCheck{name: name, run: run}
+}
+
+// Return a check that loads data covering the whole account and region once per scan, and matches the log groups
+// of every batch against it
+func accountWideCheck[T any](name string, load func(ctx context.Context, env *CheckEnv) (T, error), match func(env *CheckEnv, batch []*Verdict, data T) []Finding) Check {
+	return NewCheck(name, func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+		data, err := env.LoadOnce(name, func() (any, error) {
+			return load(ctx, env)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return match(env, batch, data.(T)), nil
+	})
+}
+
+// Registered checks in the order they are executed. The cheap checks that only need DescribeLogGroups
+// come first so the checks that call an API per log group see as few log groups as possible.
+var checkRegistry = append(append([]Check{}, describeLogGroupChecks...),
+	accountWideCheck(CheckAccountPolicy,
+		func(ctx context.Context, env *CheckEnv) ([]accountPolicy, error) {
+			return listAccountPolicies(ctx, env.Logs)
+		},
+		func(env *CheckEnv, batch []*Verdict, policies []accountPolicy) []Finding {
+			return accountPolicyFindings(batch, policies)
+		}),
+	NewCheck(CheckTag, func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+		return getTagExclusions(ctx, env.Engine, batch, env.Logs, env.Rules), nil
+	}),
+	NewCheck(CheckFieldIndex, func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+		return getAllIndexPolicies(ctx, batch, env.Logs), nil
+	}),
+	NewCheck(CheckSubscriptionFilter, func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+		return getFilteredLogListConcurrently(ctx, env.Engine, batch, env.Logs), nil
+	}),
+	NewCheck(CheckTransformer, func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+		return getTransformers(ctx, env.Engine, batch, env.Logs), nil
+	}),
+	accountWideCheck(CheckAnomalyDetector,
+		func(ctx context.Context, env *CheckEnv) (map[string][]string, error) {
+			return listAnomalyDetectors(ctx, env.Logs)
+		},
+		func(env *CheckEnv, batch []*Verdict, detectors map[string][]string) []Finding {
+			return anomalyDetectorFindings(batch, detectors)
+		}),
+	trailEventCheck(liveTailEvent),
+	trailEventCheck(exportTaskEvent),
+	trailEventCheck(logEventsReadEvent),
+	// Reading the events of a log group is the most expensive check, so it runs last
+	NewCheck(CheckEMF, func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+		return getEMFNamespaces(ctx, env.Engine, batch, env.Logs, env.Now, env.Rules.sampleSize(), env.Rules.sampleBytes()), nil
+	}),
+)
+
+// RegisterCheck adds a check that runs after the registered checks. It panics if the name is empty or
+// already registered, so it is meant to be called during initialization.
+func RegisterCheck(check Check) {
+	name := check.Name()
+	if name == "" {
+		panic("RegisterCheck: check has no name")
+	}
+	for _, registered := range checkRegistry {
+		if registered.Name() == name {
+			panic(fmt.Sprintf("RegisterCheck: check %q is already registered", name))
+		}
+	}
+	checkRegistry = append(checkRegistry, check)
+}
+
+// Return the names of all registered checks in the order they are executed
+func checkNames() []string {
+	names := make([]string, 0, len(checkRegistry))
+	for _, check := range checkRegistry {
+		names = append(names, check.Name())
+	}
+	return names
+}
+
+// Batches a check can be ahead of the next one, which bounds the log groups held in the pipeline
+const pipelineBuffer = 2
+
+// Run every enabled check in order against the batches of log groups received from batches, and send every batch
+// on once all the checks ran on it. Each check runs in its own goroutine, so the checks work on different batches
+// at the same time. A check that fails, or that has not run once ctx is cancelled, is recorded as undetermined.
+// The returned channel is closed after the last batch.
+func runChecks(ctx context.Context, env *CheckEnv, checks []Check, batches <-chan []*Verdict) <-chan []*Verdict {
+	if env.Engine == nil {
+		env.Engine = NewEngine(DefaultMaxConcurrency, DefaultRequestsPerSecond)
+	}
+	if env.cache == nil {
+		env.cache = &scanCache{entries: make(map[string]*cacheEntry)}
+	}
+	for _, check := range checks {
+		if !env.Rules.enabled(check.Name()) {
+			continue
+		}
+		out := make(chan []*Verdict, pipelineBuffer)
+		go runCheckStage(ctx, env, check, batches, out)
+		batches = out
+	}
+	return batches
+}
+
+// Run a check against the log groups still in consideration of every batch it receives and pass the batches on
+func runCheckStage(ctx context.Context, env *CheckEnv, check Check, in <-chan []*Verdict, out chan<- []*Verdict) {
+	defer close(out)
+	stage := env.Progress.Start(env.Account, env.Region, check.Name(), 0)
+	evaluated, remaining, undetermined := 0, 0, 0
+
+	for verdicts := range in {
+		batch := inConsideration(verdicts)
+		if len(batch) > 0 {
+			stage.Expect(len(batch))
+			if err := runCheck(ctx, env, check, batch); err != nil {
+				// Only the first error is logged, an account wide check fails every batch the same way
+				if undetermined == 0 {
+					log.Printf("[%s] Error running the %s check, its log groups are undetermined: %v", scanLabel(env.Account, env.Region), check.Name(), err)
+				}
+				undetermined += len(batch)
+			}
+			stage.Add(len(batch))
+			evaluated += len(batch)
+			remaining += len(inConsideration(batch))
+		}
+		out <- verdicts
+	}
+
+	stage.Done()
+	log.Printf("[%s] The %s check evaluated %d log groups, still in consideration: %d, undetermined: %d", scanLabel(env.Account, env.Region), check.Name(), evaluated, remaining, undetermined)
+}
+
+// Run a check against a batch and record its findings. If the check could not be run at all every log group of
+// the batch is undetermined and the error is returned.
+func runCheck(ctx context.Context, env *CheckEnv, check Check, batch []*Verdict) error {
+	if err := ctx.Err(); err != nil {
+		markUndetermined(check.Name(), batch, err)
+		return err
+	}
+	var findings []Finding
+	err := callRecovered(func() error {
+		var err error
+		findings, err = check.Run(ctx, env, batch)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		markUndetermined(check.Name(), batch, err)
+		return err
+	}
+	applyFindings(check.Name(), batch, findings)
+	return nil
+}
+
+// Call fn and return the panic it raised as an error. The goroutines of a scan run their work through it, so a panic
+// fails the check, log group or region it happened in instead of the whole run.
+func callRecovered(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("panic: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	return fn()
+}
+
+// Record the findings of a check on the verdicts of the batch. Log groups without a finding passed.
+func applyFindings(check string, batch []*Verdict, findings []Finding) {
+	byName := make(map[string]Finding, len(findings))
+	for _, finding := range findings {
+		byName[finding.LogGroupName] = finding
+	}
+
+	for _, verdict := range batch {
+		finding, ok := byName[verdict.LogGroupName]
+		switch {
+		case !ok:
+			verdict.pass(check)
+		case finding.Err != nil:
+			verdict.undetermined(check, finding.Err)
+		case finding.Informational:
+			verdict.pass(check)
+			verdict.note(check, finding.Detail)
+			verdict.recordUsages(finding.Usages)
+		default:
+			verdict.exclude(check, finding.Detail)
+			verdict.recordUsages(finding.Usages)
+		}
+	}
+}
+
+// Record that a check could not be evaluated for any log group of the batch
+func markUndetermined(check string, batch []*Verdict, err error) {
+	for _, verdict := range batch {
+		verdict.undetermined(check, err)
+	}
+}
@@ -0,0 +1,271 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckNames(t *testing.T) {
+	expected := []string{
+		CheckNamePattern,
+		CheckMetricFilter,
+		CheckDataProtection,
+		CheckAlreadyIA,
+		CheckInsights,
+		CheckAccountPolicy,
+		CheckTag,
+		CheckFieldIndex,
+		CheckSubscriptionFilter,
+		CheckTransformer,
+		CheckAnomalyDetector,
+		CheckLiveTail,
+		CheckExportTask,
+		CheckLogEventsRead,
+		CheckEMF,
+	}
+	if names := checkNames(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("checkNames() = %v, want %v", names, expected)
+	}
+}
+
+func TestRegisterCheck(t *testing.T) {
+	registry := checkRegistry
+	defer func() { checkRegistry = registry }()
+
+	custom := NewCheck("custom", func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+		return nil, nil
+	})
+	RegisterCheck(custom)
+	if names := checkNames(); names[len(names)-1] != "custom" {
+		t.Errorf("checkNames() = %v, want custom last", names)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterCheck() did not panic on a duplicate name")
+		}
+	}()
+	RegisterCheck(custom)
+}
+
+func TestRunChecks(t *testing.T) {
+	var seen [][]string
+	record := func(env *CheckEnv, batch []*Verdict) {
+		var names []string
+		for _, v := range batch {
+			names = append(names, v.LogGroupName)
+		}
+		seen = append(seen, names)
+	}
+
+	checks := []Check{
+		NewCheck("first", func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+			record(env, batch)
+			return []Finding{
+				{LogGroupName: "log1", Detail: "excluded by first"},
+				{LogGroupName: "log2", Err: errors.New("throttled")},
+			}, nil
+		}),
+		NewCheck("broken", func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+			record(env, batch)
+			return nil, errors.New("AccessDeniedException")
+		}),
+		NewCheck("last", func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+			record(env, batch)
+			return nil, nil
+		}),
+	}
+
+	verdicts := verdictsFromNames("log1", "log2", "log3")
+	runChecksOnBatch(context.Background(), &CheckEnv{Region: "us-west-2", Rules: DefaultRules()}, checks, verdicts)
+
+	// Every check only sees the log groups still in consideration
+	expectedSeen := [][]string{{"log1", "log2", "log3"}, {"log2", "log3"}, {"log2", "log3"}}
+	if !reflect.DeepEqual(seen, expectedSeen) {
+		t.Errorf("batches = %v, want %v", seen, expectedSeen)
+	}
+
+	if status := verdicts[0].Status(); status != StatusIneligible {
+		t.Errorf("log1 status = %v, want %v", status, StatusIneligible)
+	}
+	if status := verdicts[1].Status(); status != StatusUnknown {
+		t.Errorf("log2 status = %v, want %v", status, StatusUnknown)
+	}
+	// A check that fails as a whole leaves every log group of its batch undetermined
+	if status := verdicts[2].Status(); status != StatusUnknown {
+		t.Errorf("log3 status = %v, want %v", status, StatusUnknown)
+	}
+	if result := verdicts[2].checkResult("broken"); result != "unknown: AccessDeniedException" {
+		t.Errorf("log3 broken check = %q, want unknown: AccessDeniedException", result)
+	}
+	if passed := verdicts[2].Passed; !reflect.DeepEqual(passed, []string{"first", "last"}) {
+		t.Errorf("log3 passed = %v, want [first last]", passed)
+	}
+}
+
+func TestRunChecksStreamsBatches(t *testing.T) {
+	loads := 0
+	checks := []Check{
+		accountWideCheck("account_wide",
+			func(ctx context.Context, env *CheckEnv) (map[string]bool, error) {
+				loads++
+				return map[string]bool{"log1": true, "log4": true}, nil
+			},
+			func(env *CheckEnv, batch []*Verdict, excluded map[string]bool) []Finding {
+				var findings []Finding
+				for _, v := range batch {
+					if excluded[v.LogGroupName] {
+						findings = append(findings, Finding{LogGroupName: v.LogGroupName, Detail: "excluded"})
+					}
+				}
+				return findings
+			}),
+		NewCheck("last", func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+			return nil, nil
+		}),
+	}
+
+	batches := make(chan []*Verdict)
+	checked := runChecks(context.Background(), &CheckEnv{Rules: DefaultRules()}, checks, batches)
+	go func() {
+		batches <- verdictsFromNames("log1", "log2")
+		batches <- verdictsFromNames("log3", "log4")
+		close(batches)
+	}()
+
+	// The batches come out in order, with every check applied
+	var statuses []VerdictStatus
+	for batch := range checked {
+		for _, v := range batch {
+			statuses = append(statuses, v.Status())
+		}
+	}
+	expected := []VerdictStatus{StatusIneligible, StatusEligible, StatusEligible, StatusIneligible}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("statuses = %v, want %v", statuses, expected)
+	}
+	if loads != 1 {
+		t.Errorf("account wide data was loaded %d times, want once", loads)
+	}
+}
+
+func TestRunChecksSkipsDisabledChecks(t *testing.T) {
+	disabled := false
+	rules := &Rules{Checks: map[string]CheckRule{CheckMetricFilter: {Enabled: &disabled}}}
+	rules.compile()
+
+	ran := false
+	checks := []Check{
+		NewCheck(CheckMetricFilter, func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+			ran = true
+			return nil, nil
+		}),
+	}
+
+	verdicts := verdictsFromNames("log1")
+	runChecksOnBatch(context.Background(), &CheckEnv{Rules: rules}, checks, verdicts)
+	if ran {
+		t.Error("runChecks() ran a disabled check")
+	}
+	if len(verdicts[0].Passed) != 0 {
+		t.Errorf("passed = %v, want none", verdicts[0].Passed)
+	}
+}
+
+func TestRunChecksCancelled(t *testing.T) {
+	ran := false
+	checks := []Check{
+		NewCheck("first", func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+			ran = true
+			return nil, nil
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	verdicts := verdictsFromNames("log1")
+	runChecksOnBatch(ctx, &CheckEnv{Rules: DefaultRules()}, checks, verdicts)
+
+	if ran {
+		t.Error("runChecks() ran a check after the context was cancelled")
+	}
+	if status := verdicts[0].Status(); status != StatusUnknown {
+		t.Errorf("status = %v, want %v", status, StatusUnknown)
+	}
+}
+
+func TestRunChecksCancelledDuringCheck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	checks := []Check{
+		NewCheck("first", func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+			cancel()
+			return nil, ctx.Err()
+		}),
+	}
+
+	verdicts := verdictsFromNames("log1")
+	runChecksOnBatch(ctx, &CheckEnv{Rules: DefaultRules()}, checks, verdicts)
+
+	// The check did not finish, so the log group must not look like it passed
+	if status := verdicts[0].Status(); status != StatusUnknown {
+		t.Errorf("status = %v, want %v", status, StatusUnknown)
+	}
+}
+
+func TestRunChecksRecoversPanics(t *testing.T) {
+	checks := []Check{
+		NewCheck("panics", func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+			var usages map[string]int
+			usages[batch[0].LogGroupName]++
+			return nil, nil
+		}),
+		NewCheck("last", func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+			return nil, nil
+		}),
+	}
+
+	verdicts := verdictsFromNames("log1")
+	runChecksOnBatch(context.Background(), &CheckEnv{Rules: DefaultRules()}, checks, verdicts)
+
+	// The panic leaves the batch undetermined and the other checks still run
+	if result := verdicts[0].checkResult("panics"); result != "unknown: panicked: assignment to entry in nil map" {
+		t.Errorf("panics check = %q, want unknown: panicked: assignment to entry in nil map", result)
+	}
+	if passed := verdicts[0].Passed; !reflect.DeepEqual(passed, []string{"last"}) {
+		t.Errorf("passed = %v, want [last]", passed)
+	}
+}
+
+func TestLoadOnceRecoversPanics(t *testing.T) {
+	env := &CheckEnv{cache: &scanCache{entries: make(map[string]*cacheEntry)}}
+	for i := 0; i < 2; i++ {
+		// A load that panicked fails every batch, rather than returning a nil value to the later ones
+		if _, err := env.LoadOnce("detectors", func() (any, error) { panic("nil map") }); err == nil || err.Error() != "panicked: nil map" {
+			t.Errorf("LoadOnce() error = %v, want panicked: nil map", err)
+		}
+	}
+}
+
+// Run the checks against a single batch of log groups and wait for them to finish
+func runChecksOnBatch(ctx context.Context, env *CheckEnv, checks []Check, verdicts []*Verdict) {
+	batches := make(chan []*Verdict, 1)
+	batches <- verdicts
+	close(batches)
+	for range runChecks(ctx, env, checks, batches) {
+	}
+}
+
+// Return the registered checks with the given names, in registry order
+func checksNamed(names ...string) []Check {
+	var checks []Check
+	for _, check := range checkRegistry {
+		for _, name := range names {
+			if check.Name() == name {
+				checks = append(checks, check)
+			}
+		}
+	}
+	return checks
+}
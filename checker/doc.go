@@ -0,0 +1,10 @@
+// Package checker finds CloudWatch Logs log groups that could move to the Infrequent Access log class.
+//
+// A Scanner lists the log groups of one account and region, runs every enabled Check against them and
+// returns a Verdict per log group with the checks it passed or failed and the projected savings:
+//
+//	scanner := checker.NewScanner(cloudwatchlogs.NewFromConfig(cfg), cloudtrail.NewFromConfig(cfg), checker.Options{Region: cfg.Region})
+//	verdicts, err := scanner.Scan(ctx)
+//
+// ScanRegions and ScanAccounts build a Scanner per region and account from an aws.Config.
+package checker
@@ -0,0 +1,159 @@
+// This file contains the Embedded Metric Format check. CloudWatch does not extract the metrics of EMF events
+// ingested into Infrequent Access log groups, so a log group that receives them would silently lose its metrics.
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+const (
+	// Default number of recent events the emf check reads per log group
+	defaultEMFSampleSize = 100
+	// Most events FilterLogEvents returns in a single call
+	maxEMFSampleSize = 10000
+	// Default number of bytes of the sampled events that are parsed per log group
+	defaultEMFSampleBytes = 256 * 1024
+	// The events are sampled from this window before the scan
+	emfSampleWindow = 24 * time.Hour
+	// The window is read from its most recent slice back, starting with a slice of this length that doubles every time
+	emfFirstSlice = time.Hour
+	// Most FilterLogEvents calls per log group. The API can return empty pages while it scans the log group.
+	maxEMFPages = 20
+)
+
+// emfEvent holds the metadata of an event in the Embedded Metric Format
+type emfEvent struct {
+	AWS *struct {
+		CloudWatchMetrics []struct {
+			Namespace string `json:"Namespace"`
+		} `json:"CloudWatchMetrics"`
+	} `json:"_aws"`
+}
+
+// emfSample is the state of the sample of a log group
+type emfSample struct {
+	sampled, spent, found int
+	namespaces            map[string]bool
+}
+
+// Return the slices of the sample window, the most recent first. FilterLogEvents returns the events of a slice oldest
+// first, so the recent events are read before the older ones.
+func emfSlices(now time.Time) [][2]time.Time {
+	var slices [][2]time.Time
+	end := now
+	for length := emfFirstSlice; end.After(now.Add(-emfSampleWindow)); length *= 2 {
+		start := end.Add(-length)
+		if start.Before(now.Add(-emfSampleWindow)) {
+			start = now.Add(-emfSampleWindow)
+		}
+		slices = append(slices, [2]time.Time{start, end})
+		end = start
+	}
+	return slices
+}
+
+// EMF check. Samples up to sampleSize of the most recent events of the last emfSampleWindow of every log group, reading
+// the pages of FilterLogEvents one call at a time until the sample is full, sampleBytes are parsed or the window is
+// read, and returns a finding for the log groups with events in the Embedded Metric Format, naming their metric
+// namespaces. An empty window passes, but a log group with nothing read after maxEMFPages calls is undetermined.
+func getEMFNamespaces(ctx context.Context, engine *Engine, verdicts []*Verdict, client CloudWatchLogsClient, now time.Time, sampleSize, sampleBytes int) []Finding {
+	slices := emfSlices(now)
+	return engine.Each(ctx, verdicts, func(ctx context.Context, verdict *Verdict) (string, error) {
+		sample := &emfSample{namespaces: make(map[string]bool)}
+		pages := 0
+		for _, slice := range slices {
+			input := &cloudwatchlogs.FilterLogEventsInput{
+				LogGroupIdentifier: aws.String(verdict.LogGroupArn),
+				StartTime:          aws.Int64(slice[0].UnixMilli()),
+				EndTime:            aws.Int64(slice[1].UnixMilli()),
+			}
+			for {
+				if sample.sampled >= sampleSize || sample.spent >= sampleBytes {
+					return sample.detail(), nil
+				}
+				if pages == maxEMFPages {
+					if sample.sampled == 0 {
+						return "", fmt.Errorf("no events read in %d FilterLogEvents calls", maxEMFPages)
+					}
+					return sample.detail(), nil
+				}
+				input.Limit = aws.Int32(int32(sampleSize - sample.sampled))
+
+				var resp *cloudwatchlogs.FilterLogEventsOutput
+				err := engine.Call(ctx, "FilterLogEvents", func(ctx context.Context) error {
+					var err error
+					resp, err = client.FilterLogEvents(ctx, input, noSDKRetries)
+					return err
+				})
+				if err != nil {
+					return "", err
+				}
+				pages++
+				sample.add(resp.Events, sampleSize, sampleBytes)
+
+				if resp.NextToken == nil {
+					break
+				}
+				input.NextToken = resp.NextToken
+			}
+		}
+		return sample.detail(), nil
+	})
+}
+
+// Parse the events of a page until the sample is full or sampleBytes are spent
+func (s *emfSample) add(events []types.FilteredLogEvent, sampleSize, sampleBytes int) {
+	for _, event := range events {
+		if s.sampled >= sampleSize || s.spent >= sampleBytes {
+			return
+		}
+		message := aws.ToString(event.Message)
+		s.sampled++
+		s.spent += len(message)
+		if emfNamespaces(message, s.namespaces) {
+			s.found++
+		}
+	}
+}
+
+// Return the reason the sample excludes the log group, or an empty string if it has no EMF events
+func (s *emfSample) detail() string {
+	if s.found == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(s.namespaces))
+	for namespace := range s.namespaces {
+		names = append(names, namespace)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("embedded metric format in %d of %d sampled events, namespaces: %s", s.found, s.sampled, strings.Join(names, ", "))
+}
+
+// Return true if the message is an event in the Embedded Metric Format, a JSON object with a top-level
+// _aws.CloudWatchMetrics key, and add the namespaces of its metrics to namespaces
+func emfNamespaces(message string, namespaces map[string]bool) bool {
+	// Most events are not EMF, so they are only parsed if they can be
+	message = strings.TrimSpace(message)
+	if !strings.HasPrefix(message, "{") || !strings.Contains(message, "CloudWatchMetrics") {
+		return false
+	}
+	var event emfEvent
+	if err := json.Unmarshal([]byte(message), &event); err != nil || event.AWS == nil || event.AWS.CloudWatchMetrics == nil {
+		return false
+	}
+	for _, directive := range event.AWS.CloudWatchMetrics {
+		if directive.Namespace != "" {
+			namespaces[directive.Namespace] = true
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func TestEMFNamespaces(t *testing.T) {
+	tests := []struct {
+		name               string
+		message            string
+		expected           bool
+		expectedNamespaces []string
+	}{
+		{
+			name:               "EMF event",
+			message:            `{"_aws": {"Timestamp": 1738231200000, "CloudWatchMetrics": [{"Namespace": "Orders", "Dimensions": [["Service"]], "Metrics": [{"Name": "Latency", "Unit": "Milliseconds"}]}]}, "Service": "checkout", "Latency": 42}`,
+			expected:           true,
+			expectedNamespaces: []string{"Orders"},
+		},
+		{
+			name:               "Several namespaces",
+			message:            `  {"_aws": {"CloudWatchMetrics": [{"Namespace": "Orders"}, {"Namespace": "Payments"}]}}`,
+			expected:           true,
+			expectedNamespaces: []string{"Orders", "Payments"},
+		},
+		{name: "Plain text", message: "START RequestId: 1c2b CloudWatchMetrics Version: $LATEST"},
+		{name: "JSON without _aws", message: `{"level": "info", "msg": "CloudWatchMetrics flushed"}`},
+		{name: "Nested _aws", message: `{"detail": {"_aws": {"CloudWatchMetrics": [{"Namespace": "Orders"}]}}}`},
+		{name: "_aws without metrics", message: `{"_aws": {"Timestamp": 1738231200000}, "note": "CloudWatchMetrics"}`},
+		{name: "Truncated JSON", message: `{"_aws": {"CloudWatchMetrics": [{"Namespace": "Orders"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespaces := make(map[string]bool)
+			if found := emfNamespaces(tt.message, namespaces); found != tt.expected {
+				t.Errorf("emfNamespaces() = %v, want %v", found, tt.expected)
+			}
+			var names []string
+			for _, namespace := range tt.expectedNamespaces {
+				if !namespaces[namespace] {
+					t.Errorf("emfNamespaces() missed namespace %s", namespace)
+				}
+				names = append(names, namespace)
+			}
+			if len(namespaces) != len(names) {
+				t.Errorf("emfNamespaces() found %v, want %v", namespaces, names)
+			}
+		})
+	}
+}
+
+func TestGetEMFNamespaces(t *testing.T) {
+	emf := `{"_aws": {"CloudWatchMetrics": [{"Namespace": "Orders"}]}, "Latency": 42}`
+	events := func(messages ...string) *cloudwatchlogs.FilterLogEventsOutput {
+		output := &cloudwatchlogs.FilterLogEventsOutput{}
+		for _, message := range messages {
+			output.Events = append(output.Events, types.FilteredLogEvent{Message: aws.String(message)})
+		}
+		return output
+	}
+	emptyPageWithToken := &cloudwatchlogs.FilterLogEventsOutput{NextToken: aws.String("next")}
+
+	tests := []struct {
+		name           string
+		mockPages      []*cloudwatchlogs.FilterLogEventsOutput
+		mockResponse   *cloudwatchlogs.FilterLogEventsOutput
+		mockError      error
+		sampleBytes    int
+		expectedStatus VerdictStatus
+		expectedDetail string
+		expectedCalls  int
+	}{
+		{
+			name:           "No events in any slice of the window",
+			mockResponse:   events(),
+			sampleBytes:    defaultEMFSampleBytes,
+			expectedStatus: StatusEligible,
+			expectedCalls:  len(emfSlices(time.Now())),
+		},
+		{
+			name:           "EMF events",
+			mockPages:      []*cloudwatchlogs.FilterLogEventsOutput{events("plain text", emf, emf)},
+			mockResponse:   events(),
+			sampleBytes:    defaultEMFSampleBytes,
+			expectedStatus: StatusIneligible,
+			expectedDetail: "embedded metric format in 2 of 3 sampled events, namespaces: Orders",
+			expectedCalls:  len(emfSlices(time.Now())),
+		},
+		{
+			name:           "EMF events beyond the byte budget",
+			mockPages:      []*cloudwatchlogs.FilterLogEventsOutput{events(strings.Repeat("x", 100), emf)},
+			sampleBytes:    100,
+			expectedStatus: StatusEligible,
+			expectedCalls:  1,
+		},
+		{
+			name:           "Empty first page with a NextToken",
+			mockPages:      []*cloudwatchlogs.FilterLogEventsOutput{emptyPageWithToken, events(emf)},
+			mockResponse:   events(),
+			sampleBytes:    defaultEMFSampleBytes,
+			expectedStatus: StatusIneligible,
+			expectedDetail: "embedded metric format in 1 of 1 sampled events, namespaces: Orders",
+			expectedCalls:  len(emfSlices(time.Now())) + 1,
+		},
+		{
+			name:           "Only empty pages with a NextToken leave the verdict unknown",
+			mockResponse:   emptyPageWithToken,
+			sampleBytes:    defaultEMFSampleBytes,
+			expectedStatus: StatusUnknown,
+			expectedCalls:  maxEMFPages,
+		},
+		{
+			name:           "API error leaves the verdict unknown",
+			mockError:      errors.New("AccessDeniedException"),
+			sampleBytes:    defaultEMFSampleBytes,
+			expectedStatus: StatusUnknown,
+			expectedCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockCloudWatchLogsClient{
+				filterLogEventsPages:  tt.mockPages,
+				filterLogEventsOutput: tt.mockResponse,
+				filterLogEventsErr:    tt.mockError,
+			}
+
+			now := time.Now()
+			verdicts := verdictsFromNames("log1")
+			findings := getEMFNamespaces(context.Background(), NewEngine(DefaultMaxConcurrency, 0), verdicts, mockClient, now, defaultEMFSampleSize, tt.sampleBytes)
+			applyFindings(CheckEMF, verdicts, findings)
+
+			if status := verdicts[0].Status(); status != tt.expectedStatus {
+				t.Errorf("getEMFNamespaces() status = %v, want %v", status, tt.expectedStatus)
+			}
+			if tt.expectedDetail != "" && !reflect.DeepEqual(verdicts[0].Reasons, []Reason{{Check: CheckEMF, Detail: tt.expectedDetail}}) {
+				t.Errorf("getEMFNamespaces() reasons = %v, want %q", verdicts[0].Reasons, tt.expectedDetail)
+			}
+			inputs := mockClient.filterLogEventsInputs
+			if len(inputs) != tt.expectedCalls {
+				t.Fatalf("FilterLogEvents() called %d times, want %d", len(inputs), tt.expectedCalls)
+			}
+			// The most recent slice of the window is read first
+			if start := aws.ToInt64(inputs[0].StartTime); start != now.Add(-emfFirstSlice).UnixMilli() {
+				t.Errorf("first FilterLogEvents() StartTime = %d, want the last %v", start, emfFirstSlice)
+			}
+			if len(inputs) > 1 && tt.mockPages != nil && tt.mockPages[0] == emptyPageWithToken && aws.ToString(inputs[1].NextToken) != "next" {
+				t.Errorf("second FilterLogEvents() NextToken = %q, want next", aws.ToString(inputs[1].NextToken))
+			}
+		})
+	}
+}
+
+func TestEMFSlices(t *testing.T) {
+	now := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+	var lengths []time.Duration
+	end := now
+	for _, slice := range emfSlices(now) {
+		if !slice[1].Equal(end) {
+			t.Fatalf("emfSlices() slice %v does not end where the previous one starts", slice)
+		}
+		lengths = append(lengths, slice[1].Sub(slice[0]))
+		end = slice[0]
+	}
+	expected := []time.Duration{time.Hour, 2 * time.Hour, 4 * time.Hour, 8 * time.Hour, 9 * time.Hour}
+	if !reflect.DeepEqual(lengths, expected) || !end.Equal(now.Add(-emfSampleWindow)) {
+		t.Errorf("emfSlices() lengths = %v ending at %v, want %v ending at %v", lengths, end, expected, now.Add(-emfSampleWindow))
+	}
+}
@@ -0,0 +1,316 @@
+// This file contains the Engine, the shared worker pool that rate limits the per log group API calls and adapts to throttling.
+package checker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// Default limit of concurrent API calls per account and region
+	DefaultMaxConcurrency = 10
+	// Default limit of calls per second to each API, per account and region
+	DefaultRequestsPerSecond = 10.0
+
+	// Concurrency the engine starts with before ramping up to the maximum
+	initialConcurrency = 2
+	// Attempts per call before a throttled call is reported as an error
+	maxCallAttempts = 6
+	// Backoff before the first retry of a throttled call, doubled on every retry up to maxBackoff
+	baseBackoff = 200 * time.Millisecond
+	maxBackoff  = 10 * time.Second
+	// Lowest rate a throttled API is slowed down to
+	minRequestsPerSecond = 0.5
+)
+
+// Error codes the AWS APIs return when a quota is exceeded
+var throttlingCodes = map[string]bool{
+	"ThrottlingException":      true,
+	"Throttling":               true,
+	"TooManyRequestsException": true,
+	"RequestLimitExceeded":     true,
+}
+
+// Engine runs API calls on a worker pool shared by the checks of a scan. Each API has its own token bucket,
+// and the engine halves the concurrency and the rate of an API when a call is throttled, then ramps them
+// back up as calls succeed. Throttled calls are retried with exponential backoff.
+type Engine struct {
+	maxConcurrency int
+	// Calls per second to each API, 0 for no limit
+	maxRate float64
+
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	// Closed and replaced whenever a call finishes, to wake up the calls waiting for a slot
+	released  chan struct{}
+	successes int
+	buckets   map[string]*tokenBucket
+}
+
+// NewEngine returns an Engine that runs up to maxConcurrency calls at once and at most requestsPerSecond calls
+// per second to each API. A requestsPerSecond of 0 disables the rate limit.
+func NewEngine(maxConcurrency int, requestsPerSecond float64) *Engine {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	return &Engine{
+		maxConcurrency: maxConcurrency,
+		maxRate:        requestsPerSecond,
+		limit:          min(initialConcurrency, maxConcurrency),
+		released:       make(chan struct{}),
+		buckets:        make(map[string]*tokenBucket),
+	}
+}
+
+// ForEach evaluates every log group of the batch on the worker pool and returns a finding for the log groups
+// evaluate excludes or fails for. evaluate makes a single call to api and returns the reason the log group is
+// excluded, or an empty string if it passes. Throttled calls are retried, so evaluate may run more than once.
+func (e *Engine) ForEach(ctx context.Context, api string, batch []*Verdict, evaluate func(ctx context.Context, verdict *Verdict) (string, error)) []Finding {
+	return e.Each(ctx, batch, func(ctx context.Context, verdict *Verdict) (string, error) {
+		var detail string
+		err := e.Call(ctx, api, func(ctx context.Context) error {
+			var err error
+			detail, err = evaluate(ctx, verdict)
+			return err
+		})
+		return detail, err
+	})
+}
+
+// Each is ForEach for log groups that take several calls, e.g. to read pages. evaluate runs once per log group and
+// makes each of its calls through Call, never a Call within another.
+func (e *Engine) Each(ctx context.Context, batch []*Verdict, evaluate func(ctx context.Context, verdict *Verdict) (string, error)) []Finding {
+	var findings []Finding
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	jobs := make(chan *Verdict)
+	for i := 0; i < min(e.maxConcurrency, len(batch)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for verdict := range jobs {
+				var detail string
+				err := callRecovered(func() error {
+					var err error
+					detail, err = evaluate(ctx, verdict)
+					return err
+				})
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					findings = append(findings, Finding{LogGroupName: verdict.LogGroupName, Err: err})
+				case detail != "":
+					findings = append(findings, Finding{LogGroupName: verdict.LogGroupName, Detail: detail})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// Once ctx is cancelled the remaining calls fail right away, so every log group still gets a finding
+	for _, verdict := range batch {
+		jobs <- verdict
+	}
+	close(jobs)
+	wg.Wait()
+	return findings
+}
+
+// Call runs a single call to api once a worker slot and a token are available, retrying it while it is throttled or
+// fails with a transient error. The engine alone backs off, so the call must turn off the retries of the SDK with
+// noSDKRetries.
+func (e *Engine) Call(ctx context.Context, api string, call func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		if err := e.acquire(ctx); err != nil {
+			return err
+		}
+		if err := e.bucket(api).wait(ctx); err != nil {
+			e.release()
+			return err
+		}
+		// A panicking call must still give back its slot
+		err := callRecovered(func() error { return call(ctx) })
+		e.release()
+
+		throttled := isThrottling(err)
+		if !throttled && !isTransient(err) {
+			if err == nil {
+				e.succeeded(api)
+			}
+			return err
+		}
+		if throttled {
+			e.throttled(api)
+		}
+		if attempt == maxCallAttempts {
+			return err
+		}
+
+		// Back off with full jitter, unless the scan is cancelled
+		backoff := min(baseBackoff<<(attempt-1), maxBackoff)
+		select {
+		case <-time.After(rand.N(backoff) + 1):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Wait for a worker slot. Once ctx is cancelled no more slots are handed out.
+func (e *Engine) acquire(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e.mu.Lock()
+		if e.inFlight < e.limit {
+			e.inFlight++
+			e.mu.Unlock()
+			return nil
+		}
+		released := e.released
+		e.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Give back a worker slot
+func (e *Engine) release() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.inFlight--
+	close(e.released)
+	e.released = make(chan struct{})
+}
+
+// Ramp up after a full round of successful calls at the current concurrency
+func (e *Engine) succeeded(api string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.successes++
+	if e.successes >= e.limit && e.limit < e.maxConcurrency {
+		e.limit++
+		e.successes = 0
+	}
+	if bucket := e.buckets[api]; bucket != nil {
+		bucket.speedUp()
+	}
+}
+
+// Back off after a throttled call
+func (e *Engine) throttled(api string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.successes = 0
+	e.limit = max(1, e.limit/2)
+	if bucket := e.buckets[api]; bucket != nil {
+		bucket.slowDown()
+	}
+	log.Printf("%s is throttled, lowering the concurrency to %d", api, e.limit)
+}
+
+// Return the token bucket of an API, or nil if the calls are not rate limited
+func (e *Engine) bucket(api string) *tokenBucket {
+	if e.maxRate <= 0 {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	bucket, ok := e.buckets[api]
+	if !ok {
+		bucket = newTokenBucket(e.maxRate)
+		e.buckets[api] = bucket
+	}
+	return bucket
+}
+
+// Report whether err means the call exceeded a quota
+func isThrottling(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && throttlingCodes[apiErr.ErrorCode()]
+}
+
+// Report whether err is a failure the SDK would retry, such as a connection error or a 5xx response
+func isTransient(err error) bool {
+	return err != nil && retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// Turn off the retries of the SDK for a call the engine drives. The SDK would otherwise retry a throttled call
+// several times on its own before the engine gets to slow down and back off.
+func noSDKRetries(o *cloudwatchlogs.Options) {
+	o.Retryer = retry.AddWithMaxAttempts(o.Retryer, 1)
+}
+
+// tokenBucket hands out one token per call at a rate that can be lowered while an API is throttled
+type tokenBucket struct {
+	mu      sync.Mutex
+	maxRate float64
+	rate    float64
+	tokens  float64
+	last    time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	return &tokenBucket{maxRate: rate, rate: rate, tokens: burst(rate), last: time.Now()}
+}
+
+// Allow up to a second worth of calls at once, and at least one
+func burst(rate float64) float64 {
+	return max(1, rate)
+}
+
+// Take a token, waiting for one if the bucket is empty. A nil bucket never waits.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(burst(b.rate), b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	// Reserve the token now and wait for it to be refilled, so the callers are served in order
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Halve the rate after a throttled call
+func (b *tokenBucket) slowDown() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate = max(min(minRequestsPerSecond, b.maxRate), b.rate/2)
+}
+
+// Raise the rate by a tenth of the maximum after a successful call
+func (b *tokenBucket) speedUp() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate = min(b.maxRate, b.rate+b.maxRate/10)
+}
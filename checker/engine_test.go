@@ -0,0 +1,220 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/smithy-go"
+)
+
+var errThrottled = &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+
+func TestIsThrottling(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "No error", err: nil, want: false},
+		{name: "ThrottlingException", err: errThrottled, want: true},
+		{name: "Wrapped", err: fmt.Errorf("listing tags: %w", errThrottled), want: true},
+		{name: "Other API error", err: &smithy.GenericAPIError{Code: "AccessDeniedException"}, want: false},
+		{name: "Plain error", err: errors.New("ThrottlingException"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isThrottling(tt.err); got != tt.want {
+				t.Errorf("isThrottling(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineCallRetriesThrottledCalls(t *testing.T) {
+	engine := NewEngine(4, 0)
+	engine.limit = 4
+
+	calls := 0
+	limit := 0
+	err := engine.Call(context.Background(), "ListTagsForResource", func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errThrottled
+		}
+		limit = engine.limit
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Call() unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Call() made %d calls, want 3", calls)
+	}
+	// Two throttled calls halve the concurrency twice
+	if limit != 1 {
+		t.Errorf("limit after two throttled calls = %d, want 1", limit)
+	}
+}
+
+func TestEngineCallDoesNotRetryOtherErrors(t *testing.T) {
+	engine := NewEngine(4, 0)
+
+	calls := 0
+	accessDenied := &smithy.GenericAPIError{Code: "AccessDeniedException"}
+	err := engine.Call(context.Background(), "ListTagsForResource", func(ctx context.Context) error {
+		calls++
+		return accessDenied
+	})
+	if !errors.Is(err, accessDenied) || calls != 1 {
+		t.Errorf("Call() = %v after %d calls, want %v after 1 call", err, calls, accessDenied)
+	}
+}
+
+// statusError is a failed response with an HTTP status code
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+func TestEngineCallRetriesTransientErrors(t *testing.T) {
+	engine := NewEngine(4, 0)
+	engine.limit = 4
+
+	calls := 0
+	err := engine.Call(context.Background(), "GetTransformer", func(ctx context.Context) error {
+		calls++
+		if calls < 2 {
+			return statusError(503)
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Call() = %v after %d calls, want nil after 2 calls", err, calls)
+	}
+	// Only throttling slows the engine down
+	if engine.limit != 4 {
+		t.Errorf("limit after a transient error = %d, want 4", engine.limit)
+	}
+}
+
+func TestNoSDKRetries(t *testing.T) {
+	options := cloudwatchlogs.Options{Retryer: retry.NewStandard()}
+	noSDKRetries(&options)
+	if attempts := options.Retryer.MaxAttempts(); attempts != 1 {
+		t.Errorf("MaxAttempts() = %d, want 1", attempts)
+	}
+}
+
+func TestEngineRampsUp(t *testing.T) {
+	engine := NewEngine(5, 0)
+	if engine.limit != initialConcurrency {
+		t.Fatalf("limit = %d, want %d", engine.limit, initialConcurrency)
+	}
+
+	for i := 0; i < 50; i++ {
+		engine.succeeded("DescribeSubscriptionFilters")
+	}
+	if engine.limit != 5 {
+		t.Errorf("limit = %d, want 5", engine.limit)
+	}
+}
+
+func TestTokenBucketRate(t *testing.T) {
+	bucket := newTokenBucket(8)
+
+	bucket.slowDown()
+	if bucket.rate != 4 {
+		t.Errorf("rate after slowDown() = %v, want 4", bucket.rate)
+	}
+	for i := 0; i < 10; i++ {
+		bucket.slowDown()
+	}
+	if bucket.rate != minRequestsPerSecond {
+		t.Errorf("rate after repeated slowDown() = %v, want %v", bucket.rate, minRequestsPerSecond)
+	}
+	for i := 0; i < 20; i++ {
+		bucket.speedUp()
+	}
+	if bucket.rate != 8 {
+		t.Errorf("rate after repeated speedUp() = %v, want 8", bucket.rate)
+	}
+}
+
+func TestEngineForEach(t *testing.T) {
+	engine := NewEngine(3, 0)
+	verdicts := verdictsFromNames("keep", "exclude", "broken", "throttled-once", "panics")
+
+	throttled := false
+	findings := engine.ForEach(context.Background(), "ListTagsForResource", verdicts, func(ctx context.Context, verdict *Verdict) (string, error) {
+		switch verdict.LogGroupName {
+		case "exclude":
+			return "excluded", nil
+		case "broken":
+			return "", errors.New("AccessDeniedException")
+		case "throttled-once":
+			if !throttled {
+				throttled = true
+				return "", errThrottled
+			}
+		case "panics":
+			panic("nil map")
+		}
+		return "", nil
+	})
+	applyFindings(CheckTag, verdicts, findings)
+
+	expected := map[string]VerdictStatus{
+		"keep":           StatusEligible,
+		"exclude":        StatusIneligible,
+		"broken":         StatusUnknown,
+		"throttled-once": StatusEligible,
+		"panics":         StatusUnknown,
+	}
+	for _, v := range verdicts {
+		if status := v.Status(); status != expected[v.LogGroupName] {
+			t.Errorf("%s status = %v, want %v", v.LogGroupName, status, expected[v.LogGroupName])
+		}
+	}
+}
+
+func TestEngineEachRecoversPanics(t *testing.T) {
+	engine := NewEngine(1, 0)
+	verdicts := verdictsFromNames("panics", "keep")
+	findings := engine.Each(context.Background(), verdicts, func(ctx context.Context, verdict *Verdict) (string, error) {
+		if verdict.LogGroupName == "panics" {
+			panic("nil map")
+		}
+		return "", nil
+	})
+	applyFindings(CheckEMF, verdicts, findings)
+
+	if result := verdicts[0].checkResult(CheckEMF); result != "unknown: panicked: nil map" {
+		t.Errorf("panics result = %q, want unknown: panicked: nil map", result)
+	}
+	if status := verdicts[1].Status(); status != StatusEligible {
+		t.Errorf("keep status = %v, want %v", status, StatusEligible)
+	}
+}
+
+func TestEngineForEachCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	verdicts := verdictsFromNames("log1", "log2", "log3")
+	findings := NewEngine(2, DefaultRequestsPerSecond).ForEach(ctx, "ListTagsForResource", verdicts, func(ctx context.Context, verdict *Verdict) (string, error) {
+		return "", nil
+	})
+	applyFindings(CheckTag, verdicts, findings)
+
+	// Log groups that were never evaluated must not pass
+	for _, v := range verdicts {
+		if status := v.Status(); status != StatusUnknown {
+			t.Errorf("%s status = %v, want %v", v.LogGroupName, status, StatusUnknown)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// This file contains the self-contained HTML report writer. All styles and scripts are inlined so the file opens offline.
+package checker
+
+import (
+	_ "embed"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed report.html.tmpl
+var htmlReportTemplate string
+
+// Maximum number of prefixes shown in the per-prefix chart
+const maxHTMLPrefixes = 15
+
+// funnelStep is how many log groups a single check removed and how many were left after it
+type funnelStep struct {
+	Check     string
+	Removed   int
+	Remaining int
+	Percent   float64
+}
+
+// prefixStat is the breakdown of a log group name prefix such as /aws/lambda/
+type prefixStat struct {
+	Prefix      string
+	Candidates  int
+	Rejected    int
+	Unknown     int
+	Savings     float64
+	CandidatePc float64
+	RejectedPc  float64
+	UnknownPc   float64
+}
+
+// htmlRow is a single log group in the table
+type htmlRow struct {
+	Account         string
+	Region          string
+	Name            string
+	Status          string
+	Class           string
+	RetentionInDays int32
+	StoredBytes     int64
+	MonthlySavings  float64
+	Reasons         string
+}
+
+// htmlPrincipal is a principal that used Standard-only APIs and the log groups it used them on
+type htmlPrincipal struct {
+	Principal string
+	Events    int
+	LastUsed  string
+	LogGroups string
+}
+
+type htmlReportData struct {
+	Run              RunInfo
+	Accounts         string
+	Regions          string
+	Failures         []ScanFailure
+	Undetermined     []htmlRow
+	Principals       []htmlPrincipal
+	GeneratedAt      string
+	WindowStart      string
+	WindowEnd        string
+	Total            int
+	Candidates       int
+	CandidateSavings float64
+	AnnualSavings    float64
+	Funnel           []funnelStep
+	Prefixes         []prefixStat
+	Rows             []htmlRow
+}
+
+// Write the report as a single HTML file with the funnel, per-prefix charts and a filterable table
+func writeHTMLReport(fileName string, report *Report) error {
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"money": FormatMoney,
+	}).Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return tmpl.Execute(file, newHTMLReportData(report))
+}
+
+func newHTMLReportData(report *Report) htmlReportData {
+	data := htmlReportData{
+		Run:         report.Run,
+		Accounts:    strings.Join(report.Run.Accounts, ", "),
+		Regions:     strings.Join(report.Run.Regions, ", "),
+		Failures:    report.Failures,
+		GeneratedAt: report.Run.GeneratedAt.Format(time.RFC1123),
+		WindowStart: report.Run.WindowStart.Format("2006-01-02"),
+		WindowEnd:   report.Run.WindowEnd.Format("2006-01-02"),
+		Total:       len(report.Verdicts),
+		Funnel:      funnel(report.Verdicts, report.Run.ChecksExecuted),
+		Prefixes:    prefixStats(report.Verdicts),
+
+		CandidateSavings: report.Run.TotalMonthlySavings,
+		AnnualSavings:    report.Run.TotalAnnualSavings,
+	}
+
+	for _, v := range report.Verdicts {
+		if v.Status() == StatusEligible {
+			data.Candidates++
+		}
+		row := htmlRow{
+			Account:         v.Account,
+			Region:          v.Region,
+			Name:            v.LogGroupName,
+			Status:          string(v.Status()),
+			Class:           v.LogGroupClass,
+			RetentionInDays: v.RetentionInDays,
+			StoredBytes:     v.StoredBytes,
+			MonthlySavings:  v.MonthlySavings,
+			Reasons:         v.reasonSummary(),
+		}
+		data.Rows = append(data.Rows, row)
+		if v.Status() == StatusUnknown {
+			data.Undetermined = append(data.Undetermined, row)
+		}
+	}
+
+	for _, record := range principalRecords(report.Verdicts) {
+		var logGroups []string
+		for _, logGroup := range record.LogGroups {
+			logGroups = append(logGroups, logGroup.LogGroupName)
+		}
+		data.Principals = append(data.Principals, htmlPrincipal{
+			Principal: record.Principal,
+			Events:    record.Events,
+			LastUsed:  record.LastUsed.UTC().Format("2006-01-02 15:04"),
+			LogGroups: strings.Join(logGroups, ", "),
+		})
+	}
+
+	return data
+}
+
+// Count how many log groups each check removed. Checks run in order and skip excluded groups,
+// so the first reason of a verdict is the stage that removed it.
+func funnel(verdicts []*Verdict, checks []string) []funnelStep {
+	removed := make(map[string]int)
+	for _, v := range verdicts {
+		if len(v.Reasons) > 0 {
+			removed[v.Reasons[0].Check]++
+		}
+	}
+
+	remaining := len(verdicts)
+	var steps []funnelStep
+	for _, check := range checks {
+		remaining -= removed[check]
+		step := funnelStep{Check: check, Removed: removed[check], Remaining: remaining}
+		if len(verdicts) > 0 {
+			step.Percent = float64(remaining) / float64(len(verdicts)) * 100
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// Break the verdicts down by name prefix, largest prefixes first
+func prefixStats(verdicts []*Verdict) []prefixStat {
+	byPrefix := make(map[string]*prefixStat)
+	for _, v := range verdicts {
+		prefix := logGroupPrefix(v.LogGroupName)
+		stat, ok := byPrefix[prefix]
+		if !ok {
+			stat = &prefixStat{Prefix: prefix}
+			byPrefix[prefix] = stat
+		}
+		switch v.Status() {
+		case StatusEligible:
+			stat.Candidates++
+			stat.Savings += v.MonthlySavings
+		case StatusIneligible:
+			stat.Rejected++
+		default:
+			stat.Unknown++
+		}
+	}
+
+	var stats []prefixStat
+	for _, stat := range byPrefix {
+		total := float64(stat.Candidates + stat.Rejected + stat.Unknown)
+		stat.CandidatePc = float64(stat.Candidates) / total * 100
+		stat.RejectedPc = float64(stat.Rejected) / total * 100
+		stat.UnknownPc = float64(stat.Unknown) / total * 100
+		stats = append(stats, *stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		ti := stats[i].Candidates + stats[i].Rejected + stats[i].Unknown
+		tj := stats[j].Candidates + stats[j].Rejected + stats[j].Unknown
+		if ti != tj {
+			return ti > tj
+		}
+		return stats[i].Prefix < stats[j].Prefix
+	})
+	if len(stats) > maxHTMLPrefixes {
+		stats = stats[:maxHTMLPrefixes]
+	}
+	return stats
+}
+
+// Return the prefix a log group is grouped under, e.g. /aws/lambda/ for /aws/lambda/my-function and /ecs/ for /ecs/my-service
+func logGroupPrefix(name string) string {
+	if !strings.HasPrefix(name, "/") {
+		return "(no prefix)"
+	}
+
+	parts := strings.SplitN(name, "/", 4)
+	if parts[1] == "aws" && len(parts) > 3 {
+		return "/aws/" + parts[2] + "/"
+	}
+	if len(parts) > 2 {
+		return "/" + parts[1] + "/"
+	}
+	return "(no prefix)"
+}
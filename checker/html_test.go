@@ -0,0 +1,106 @@
+package checker
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	tempFile := "test_output.html"
+	defer os.Remove(tempFile)
+
+	report := testReport()
+	report.Verdicts[0].MonthlySavings = 12345.6
+	report.Verdicts[1].LogGroupName = "<script>alert(1)</script>"
+
+	if err := WriteReport(tempFile, FormatHTML, report); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	html := string(content)
+
+	if !strings.Contains(html, "<td>log1</td>") {
+		t.Errorf("report does not list log1")
+	}
+	// Numeric columns sort on the raw value rather than the formatted text
+	if !strings.Contains(html, `<td class="num" data-value="12345.60">12,345.60</td>`) {
+		t.Errorf("report does not carry the raw savings of log1 in data-value")
+	}
+	if strings.Contains(html, "<script>alert(1)</script>") {
+		t.Errorf("log group names are not escaped")
+	}
+	// Everything must be embedded so the report opens without network access
+	for _, external := range []string{"<script src", "<link ", "http://", "https://"} {
+		if strings.Contains(html, external) {
+			t.Errorf("report references external resources: %q", external)
+		}
+	}
+}
+
+func TestWriteHTMLReportUndetermined(t *testing.T) {
+	tempFile := "test_output_undetermined.html"
+	defer os.Remove(tempFile)
+
+	report := testReport()
+	if err := WriteReport(tempFile, FormatHTML, report); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	content, _ := os.ReadFile(tempFile)
+	if strings.Contains(string(content), "could not be fully evaluated") {
+		t.Errorf("report has an undetermined section without undetermined log groups")
+	}
+
+	report.Verdicts[0].undetermined(CheckFieldIndex, errors.New("ThrottlingException"))
+	if err := WriteReport(tempFile, FormatHTML, report); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	content, _ = os.ReadFile(tempFile)
+	if !strings.Contains(string(content), "could not be fully evaluated") || !strings.Contains(string(content), "ThrottlingException") {
+		t.Errorf("report does not list the undetermined log group")
+	}
+}
+
+func TestFunnel(t *testing.T) {
+	verdicts := verdictsFromNames("log1", "log2", "log3", "log4")
+	verdicts[0].exclude(CheckMetricFilter, "1 metric filters")
+	verdicts[0].exclude(CheckInsights, "insights")
+	verdicts[1].exclude(CheckSubscriptionFilter, "filter")
+	verdicts[2].exclude(CheckExportTask, "1 CreateExportTask events")
+
+	removed := make(map[string]funnelStep)
+	for _, step := range funnel(verdicts, checkNames()) {
+		removed[step.Check] = step
+	}
+
+	if step := removed[CheckMetricFilter]; step.Removed != 1 || step.Remaining != 3 {
+		t.Errorf("metric_filter step = %+v, want 1 removed and 3 remaining", step)
+	}
+	if step := removed[CheckInsights]; step.Removed != 0 {
+		t.Errorf("insights step = %+v, want only the first reason counted", step)
+	}
+	if step := removed[CheckExportTask]; step.Remaining != 1 || step.Percent != 25 {
+		t.Errorf("export_task step = %+v, want 1 remaining at 25%%", step)
+	}
+}
+
+func TestLogGroupPrefix(t *testing.T) {
+	tests := map[string]string{
+		"/aws/lambda/my-function":    "/aws/lambda/",
+		"/aws/ecs/containerinsights": "/aws/ecs/",
+		"/ecs/my-service":            "/ecs/",
+		"/aws/lambda":                "/aws/",
+		"/single":                    "(no prefix)",
+		"my-application":             "(no prefix)",
+	}
+	for name, expected := range tests {
+		if result := logGroupPrefix(name); result != expected {
+			t.Errorf("logGroupPrefix(%s) = %v, want %v", name, result, expected)
+		}
+	}
+}
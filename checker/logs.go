@@ -0,0 +1,361 @@
+// This file will containt the functions utilized for making cloudwatchlogs client calls.
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// CloudWatchLogsClient is an interface for CloudWatch Logs operations
+type CloudWatchLogsClient interface {
+	DescribeLogGroups(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
+	DescribeFieldIndexes(ctx context.Context, params *cloudwatchlogs.DescribeFieldIndexesInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeFieldIndexesOutput, error)
+	DescribeSubscriptionFilters(ctx context.Context, params *cloudwatchlogs.DescribeSubscriptionFiltersInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeSubscriptionFiltersOutput, error)
+	ListLogAnomalyDetectors(ctx context.Context, params *cloudwatchlogs.ListLogAnomalyDetectorsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListLogAnomalyDetectorsOutput, error)
+	ListTagsForResource(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error)
+	DescribeAccountPolicies(ctx context.Context, params *cloudwatchlogs.DescribeAccountPoliciesInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeAccountPoliciesOutput, error)
+	GetTransformer(ctx context.Context, params *cloudwatchlogs.GetTransformerInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetTransformerOutput, error)
+	FilterLogEvents(ctx context.Context, params *cloudwatchlogs.FilterLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error)
+}
+
+// Send a batch with a verdict for every log group of each page of DescribeLogGroups to pages as soon as it is
+// read, so the checks can start on it while the next page is read. Every log group is counted on stage.
+// pages is closed when all the log groups were listed or an error stopped the listing.
+func getLogList(ctx context.Context, client CloudWatchLogsClient, stage *Stage, pages chan<- []*Verdict) error {
+	defer close(pages)
+
+	//Create paginator so i can get all the log groups
+	describeLogsPaginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(client, &cloudwatchlogs.DescribeLogGroupsInput{})
+
+	for describeLogsPaginator.HasMorePages() {
+		output, err := describeLogsPaginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		batch := make([]*Verdict, 0, len(output.LogGroups))
+		for _, value := range output.LogGroups {
+			batch = append(batch, newVerdict(value))
+		}
+		stage.Add(len(batch))
+		pages <- batch
+	}
+
+	return nil
+}
+
+// Checks that only need the output of DescribeLogGroups
+var describeLogGroupChecks = []Check{
+	logGroupCheck(CheckNamePattern, nameCondition),
+	logGroupCheck(CheckMetricFilter, metricFilterCondition),
+	logGroupCheck(CheckDataProtection, dataProtectionCondition),
+	logGroupCheck(CheckAlreadyIA, alreadyIACondition),
+	NewCheck(CheckInsights, func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+		return managedLogGroupFindings(batch, env.Catalog, env.Rules), nil
+	}),
+}
+
+// Return a check that only needs the output of DescribeLogGroups. The condition returns the reason
+// the log group is excluded, or an empty string if it passes.
+func logGroupCheck(name string, condition func(verdict *Verdict, rules *Rules) string) Check {
+	return NewCheck(name, func(ctx context.Context, env *CheckEnv, batch []*Verdict) ([]Finding, error) {
+		var findings []Finding
+		for _, verdict := range batch {
+			if detail := condition(verdict, env.Rules); detail != "" {
+				findings = append(findings, Finding{LogGroupName: verdict.LogGroupName, Detail: detail})
+			}
+		}
+		return findings, nil
+	})
+}
+
+func nameCondition(verdict *Verdict, rules *Rules) string {
+	return rules.nameExclusion(verdict.LogGroupName)
+}
+
+func metricFilterCondition(verdict *Verdict, rules *Rules) string {
+	if hasMetricFilter(verdict.logGroup) {
+		return fmt.Sprintf("%d metric filters", aws.ToInt32(verdict.logGroup.MetricFilterCount))
+	}
+	return ""
+}
+
+func dataProtectionCondition(verdict *Verdict, rules *Rules) string {
+	if hasDataProtectionPolicy(verdict.logGroup) {
+		return "data protection policy is activated"
+	}
+	return ""
+}
+
+func alreadyIACondition(verdict *Verdict, rules *Rules) string {
+	if isIA(verdict.logGroup) {
+		return "log group class is already INFREQUENT_ACCESS"
+	}
+	return ""
+}
+
+// Check if already IA
+func isIA(logGroup types.LogGroup) bool {
+	if logGroup.LogGroupClass == types.LogGroupClassInfrequentAccess {
+		return true
+	}
+	return false
+}
+
+// check for metric filters
+func hasMetricFilter(logGroup types.LogGroup) bool {
+	return aws.ToInt32(logGroup.MetricFilterCount) > 0
+}
+
+// check for data protection policy
+func hasDataProtectionPolicy(logGroup types.LogGroup) bool {
+	if logGroup.DataProtectionStatus == types.DataProtectionStatusActivated {
+		return true
+	}
+	return false
+}
+
+// Tag check. Returns a finding for the log groups carrying one of the excluded tags.
+func getTagExclusions(ctx context.Context, engine *Engine, verdicts []*Verdict, client CloudWatchLogsClient, rules *Rules) []Finding {
+	return engine.ForEach(ctx, "ListTagsForResource", verdicts, func(ctx context.Context, verdict *Verdict) (string, error) {
+		resp, err := client.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{
+			ResourceArn: aws.String(verdict.LogGroupArn),
+		}, noSDKRetries)
+		if err != nil {
+			return "", err
+		}
+		return rules.tagExclusion(resp.Tags), nil
+	})
+}
+
+// Index Policy Checks. Returns a finding for the log groups that have index policies, and for every other
+// log group of a batch DescribeFieldIndexes failed for.
+func getAllIndexPolicies(ctx context.Context, verdicts []*Verdict, client CloudWatchLogsClient) []Finding {
+	const batchSize = 100
+	var findings []Finding
+	remaining := verdicts
+
+	// Split the remaining log groups into chunks of batchSize
+	for i := 0; i < len(remaining); i += batchSize {
+		end := i + batchSize
+		if end > len(remaining) {
+			end = len(remaining)
+		}
+		batch := make([]string, 0, end-i)
+		for _, v := range remaining[i:end] {
+			batch = append(batch, v.LogGroupArn)
+		}
+
+		// Call DescribeFieldIndexes and record the log groups that have index policies. The pages read before
+		// an error still prove a log group is indexed, but no other log group of the batch can be trusted.
+		indexed, err := fetchIndexPoliciesForBatch(ctx, batch, client)
+		for _, v := range remaining[i:end] {
+			if fields, ok := indexed[v.LogGroupName]; ok {
+				findings = append(findings, Finding{LogGroupName: v.LogGroupName, Detail: "indexed fields: " + strings.Join(fields, ", ")})
+			} else if err != nil {
+				findings = append(findings, Finding{LogGroupName: v.LogGroupName, Err: err})
+			}
+		}
+	}
+	return findings
+}
+
+// Return the field index names per log group name for the log groups in the batch that have index policies.
+// On error the index policies found so far are returned with it.
+func fetchIndexPoliciesForBatch(ctx context.Context, batch []string, client CloudWatchLogsClient) (map[string][]string, error) {
+	var nextToken *string
+	indexed := make(map[string][]string)
+
+	for {
+		// Call DescribeFieldIndexes with the current batch of log groups
+		resp, err := client.DescribeFieldIndexes(ctx, &cloudwatchlogs.DescribeFieldIndexesInput{
+			LogGroupIdentifiers: batch,
+			NextToken:           nextToken, // Set the next token from the previous page
+		})
+		if err != nil {
+			log.Printf("Error describing index policies: %v", err)
+			return indexed, err
+		}
+
+		// Record the indexed fields of log groups with index policies
+		for _, policy := range resp.FieldIndexes {
+			logGroupName := logGroupNameFromIdentifier(aws.ToString(policy.LogGroupIdentifier))
+			indexed[logGroupName] = append(indexed[logGroupName], aws.ToString(policy.FieldIndexName))
+		}
+
+		// If NextToken is nil, we've retrieved all pages
+		if resp.NextToken == nil {
+			break
+		}
+
+		// Update the nextToken for the next request
+		nextToken = resp.NextToken
+	}
+
+	return indexed, nil
+}
+
+// Subscription filter check. Returns a finding for the log groups that have subscription filters.
+func getFilteredLogListConcurrently(ctx context.Context, engine *Engine, verdicts []*Verdict, client CloudWatchLogsClient) []Finding {
+	return engine.ForEach(ctx, "DescribeSubscriptionFilters", verdicts, func(ctx context.Context, verdict *Verdict) (string, error) {
+		resp, err := client.DescribeSubscriptionFilters(ctx, &cloudwatchlogs.DescribeSubscriptionFiltersInput{
+			LogGroupName: aws.String(verdict.LogGroupName),
+		}, noSDKRetries)
+		if err != nil {
+			return "", err
+		}
+
+		// If subscription filters are found, record them as the reason
+		var filters []string
+		for _, filter := range resp.SubscriptionFilters {
+			filters = append(filters, fmt.Sprintf("%s -> %s", aws.ToString(filter.FilterName), aws.ToString(filter.DestinationArn)))
+		}
+		return strings.Join(filters, ", "), nil
+	})
+}
+
+// Transformer check. Returns a finding for the log groups that have a transformer, and records its processors on
+// the verdict.
+func getTransformers(ctx context.Context, engine *Engine, verdicts []*Verdict, client CloudWatchLogsClient) []Finding {
+	return engine.ForEach(ctx, "GetTransformer", verdicts, func(ctx context.Context, verdict *Verdict) (string, error) {
+		resp, err := client.GetTransformer(ctx, &cloudwatchlogs.GetTransformerInput{
+			LogGroupIdentifier: aws.String(verdict.LogGroupArn),
+		}, noSDKRetries)
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if len(resp.TransformerConfig) == 0 {
+			return "", nil
+		}
+
+		// Each verdict of the batch is only evaluated by one worker, so the configuration is recorded right away
+		verdict.Transformer = resp.TransformerConfig
+		return "transformer processors: " + strings.Join(processorNames(resp.TransformerConfig), ", "), nil
+	})
+}
+
+// Return the name of every processor of a transformer as in the API, e.g. parseJSON or addKeys
+func processorNames(processors []types.Processor) []string {
+	names := make([]string, 0, len(processors))
+	for _, processor := range transformerConfig(processors) {
+		for name := range processor {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Return the configuration of a transformer as in the API, e.g. [{"parseJSON": {}}, {"addKeys": {"entries": [...]}}].
+// A processor sets exactly one of the fields of types.Processor.
+func transformerConfig(processors []types.Processor) []map[string]interface{} {
+	config := make([]map[string]interface{}, 0, len(processors))
+	for _, processor := range processors {
+		value := reflect.ValueOf(processor)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			if field.Kind() == reflect.Pointer && !field.IsNil() {
+				config = append(config, map[string]interface{}{apiName(value.Type().Field(i).Name): apiValue(field.Interface())})
+			}
+		}
+	}
+	return config
+}
+
+// Return a value of the SDK as in the JSON of the API, with the first letter of the field names in lower case and
+// without the unset fields
+func apiValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil
+	}
+	var convert func(interface{}) interface{}
+	convert = func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			object := make(map[string]interface{}, len(value))
+			for key, item := range value {
+				if item != nil {
+					object[apiName(key)] = convert(item)
+				}
+			}
+			return object
+		case []interface{}:
+			for i, item := range value {
+				value[i] = convert(item)
+			}
+		}
+		return value
+	}
+	return convert(decoded)
+}
+
+// Return the API name of a field of the SDK, e.g. parseJSON for ParseJSON
+func apiName(field string) string {
+	return strings.ToLower(field[:1]) + field[1:]
+}
+
+// Return the names of the anomaly detectors watching each log group, keyed by log group name
+func listAnomalyDetectors(ctx context.Context, client CloudWatchLogsClient) (map[string][]string, error) {
+	var nextToken *string
+
+	// Create a map to store the detectors per log group for faster lookups
+	anomalyLogGroups := make(map[string][]string)
+
+	for {
+		// Make the ListLogAnomalyDetectors API call
+		input := &cloudwatchlogs.ListLogAnomalyDetectorsInput{
+			NextToken: nextToken, // For pagination
+		}
+
+		resp, err := client.ListLogAnomalyDetectors(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("listing anomaly detectors: %w", err)
+		}
+
+		// Process the results and add log group names to the anomalyLogGroups map
+		for _, detector := range resp.AnomalyDetectors {
+			// Loop through the list of log group ARNs that the detector watches
+			for _, logGroupArn := range detector.LogGroupArnList {
+				logGroupName := parseLogGroupArn(aws.String(logGroupArn))
+				anomalyLogGroups[logGroupName] = append(anomalyLogGroups[logGroupName], aws.ToString(detector.DetectorName))
+			}
+		}
+
+		// Check if there's a NextToken for pagination
+		if resp.NextToken == nil {
+			break // No more pages, so exit the loop
+		}
+
+		// Set the nextToken for the next request
+		nextToken = resp.NextToken
+	}
+
+	return anomalyLogGroups, nil
+}
+
+// Anomaly detector check. Returns a finding for the log groups watched by an anomaly detector.
+func anomalyDetectorFindings(verdicts []*Verdict, anomalyLogGroups map[string][]string) []Finding {
+	var findings []Finding
+	for _, verdict := range verdicts {
+		if detectors, ok := anomalyLogGroups[verdict.LogGroupName]; ok {
+			findings = append(findings, Finding{LogGroupName: verdict.LogGroupName, Detail: "anomaly detectors: " + strings.Join(detectors, ", ")})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,625 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// CloudWatchLogsAPI defines the interface for CloudWatch Logs client operations
+type CloudWatchLogsAPI interface {
+	DescribeLogGroups(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
+	DescribeFieldIndexes(ctx context.Context, params *cloudwatchlogs.DescribeFieldIndexesInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeFieldIndexesOutput, error)
+	DescribeSubscriptionFilters(ctx context.Context, params *cloudwatchlogs.DescribeSubscriptionFiltersInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeSubscriptionFiltersOutput, error)
+	ListLogAnomalyDetectors(ctx context.Context, params *cloudwatchlogs.ListLogAnomalyDetectorsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListLogAnomalyDetectorsOutput, error)
+	ListTagsForResource(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error)
+	DescribeAccountPolicies(ctx context.Context, params *cloudwatchlogs.DescribeAccountPoliciesInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeAccountPoliciesOutput, error)
+	GetTransformer(ctx context.Context, params *cloudwatchlogs.GetTransformerInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetTransformerOutput, error)
+	FilterLogEvents(ctx context.Context, params *cloudwatchlogs.FilterLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error)
+}
+
+// Mock CloudWatchLogs client for testing
+type mockCloudWatchLogsClient struct {
+	CloudWatchLogsAPI
+	describeLogGroupsOutput *cloudwatchlogs.DescribeLogGroupsOutput
+	describeLogGroupsErr    error
+	
+	describeFieldIndexesOutput *cloudwatchlogs.DescribeFieldIndexesOutput
+	describeFieldIndexesErr    error
+	
+	describeSubscriptionFiltersOutput *cloudwatchlogs.DescribeSubscriptionFiltersOutput
+	describeSubscriptionFiltersErr    error
+	
+	listLogAnomalyDetectorsOutput *cloudwatchlogs.ListLogAnomalyDetectorsOutput
+	listLogAnomalyDetectorsErr    error
+
+	listTagsForResourceOutput *cloudwatchlogs.ListTagsForResourceOutput
+	listTagsForResourceErr    error
+
+	// Returned by DescribeAccountPolicies for their policy type
+	accountPolicies            []types.AccountPolicy
+	describeAccountPoliciesErr error
+
+	getTransformerOutput *cloudwatchlogs.GetTransformerOutput
+	getTransformerErr    error
+
+	// FilterLogEvents returns the pages in order, then filterLogEventsOutput
+	filterLogEventsPages  []*cloudwatchlogs.FilterLogEventsOutput
+	filterLogEventsOutput *cloudwatchlogs.FilterLogEventsOutput
+	filterLogEventsErr    error
+	filterLogEventsInputs []cloudwatchlogs.FilterLogEventsInput
+	filterLogEventsMu     sync.Mutex
+}
+
+func (m *mockCloudWatchLogsClient) DescribeLogGroups(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	return m.describeLogGroupsOutput, m.describeLogGroupsErr
+}
+
+func (m *mockCloudWatchLogsClient) DescribeFieldIndexes(ctx context.Context, params *cloudwatchlogs.DescribeFieldIndexesInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeFieldIndexesOutput, error) {
+	return m.describeFieldIndexesOutput, m.describeFieldIndexesErr
+}
+
+func (m *mockCloudWatchLogsClient) DescribeSubscriptionFilters(ctx context.Context, params *cloudwatchlogs.DescribeSubscriptionFiltersInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeSubscriptionFiltersOutput, error) {
+	return m.describeSubscriptionFiltersOutput, m.describeSubscriptionFiltersErr
+}
+
+func (m *mockCloudWatchLogsClient) ListLogAnomalyDetectors(ctx context.Context, params *cloudwatchlogs.ListLogAnomalyDetectorsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListLogAnomalyDetectorsOutput, error) {
+	return m.listLogAnomalyDetectorsOutput, m.listLogAnomalyDetectorsErr
+}
+
+func (m *mockCloudWatchLogsClient) ListTagsForResource(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+	return m.listTagsForResourceOutput, m.listTagsForResourceErr
+}
+
+func (m *mockCloudWatchLogsClient) DescribeAccountPolicies(ctx context.Context, params *cloudwatchlogs.DescribeAccountPoliciesInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeAccountPoliciesOutput, error) {
+	output := &cloudwatchlogs.DescribeAccountPoliciesOutput{}
+	for _, policy := range m.accountPolicies {
+		if policy.PolicyType == params.PolicyType {
+			output.AccountPolicies = append(output.AccountPolicies, policy)
+		}
+	}
+	return output, m.describeAccountPoliciesErr
+}
+
+func (m *mockCloudWatchLogsClient) GetTransformer(ctx context.Context, params *cloudwatchlogs.GetTransformerInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetTransformerOutput, error) {
+	return m.getTransformerOutput, m.getTransformerErr
+}
+
+func (m *mockCloudWatchLogsClient) FilterLogEvents(ctx context.Context, params *cloudwatchlogs.FilterLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	m.filterLogEventsMu.Lock()
+	defer m.filterLogEventsMu.Unlock()
+	m.filterLogEventsInputs = append(m.filterLogEventsInputs, *params)
+	if len(m.filterLogEventsPages) > 0 {
+		page := m.filterLogEventsPages[0]
+		m.filterLogEventsPages = m.filterLogEventsPages[1:]
+		return page, nil
+	}
+	return m.filterLogEventsOutput, m.filterLogEventsErr
+}
+
+func TestCheckLogGroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		logGroup types.LogGroup
+		expected bool
+	}{
+		{
+			name: "Log group with metric filter",
+			logGroup: types.LogGroup{
+				LogGroupName:     aws.String("test-log-group"),
+				MetricFilterCount: aws.Int32(1),
+			},
+			expected: true,
+		},
+		{
+			name: "Log group with data protection policy",
+			logGroup: types.LogGroup{
+				LogGroupName:        aws.String("test-log-group"),
+				DataProtectionStatus: types.DataProtectionStatusActivated,
+			},
+			expected: true,
+		},
+		{
+			name: "Log group already in IA",
+			logGroup: types.LogGroup{
+				LogGroupName:  aws.String("test-log-group"),
+				LogGroupClass: types.LogGroupClassInfrequentAccess,
+			},
+			expected: true,
+		},
+		{
+			name: "Lambda Insights log group",
+			logGroup: types.LogGroup{
+				LogGroupName: aws.String("/aws/lambda-insights"),
+			},
+			expected: true,
+		},
+		{
+			name: "Container Insights log group",
+			logGroup: types.LogGroup{
+				LogGroupName: aws.String("/aws/containerinsights/my-cluster/performance"),
+			},
+			expected: true,
+		},
+		{
+			name: "User log group named after insights",
+			logGroup: types.LogGroup{
+				LogGroupName: aws.String("lambda-insights-log-group"),
+			},
+			expected: false,
+		},
+		{
+			name: "Log group with no special conditions",
+			logGroup: types.LogGroup{
+				LogGroupName: aws.String("regular-log-group"),
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := newVerdict(tt.logGroup)
+			runChecksOnBatch(context.Background(), &CheckEnv{Rules: DefaultRules()}, describeLogGroupChecks, []*Verdict{verdict})
+			if (verdict.Status() == StatusIneligible) != tt.expected {
+				t.Errorf("verdict status = %v, reasons %v", verdict.Status(), verdict.Reasons)
+			}
+		})
+	}
+}
+
+func TestIsIA(t *testing.T) {
+	tests := []struct {
+		name     string
+		logGroup types.LogGroup
+		expected bool
+	}{
+		{
+			name: "Log group in IA class",
+			logGroup: types.LogGroup{
+				LogGroupClass: types.LogGroupClassInfrequentAccess,
+			},
+			expected: true,
+		},
+		{
+			name: "Log group in standard class",
+			logGroup: types.LogGroup{
+				LogGroupClass: types.LogGroupClassStandard,
+			},
+			expected: false,
+		},
+		{
+			name:     "Log group with no class specified",
+			logGroup: types.LogGroup{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := isIA(tt.logGroup)
+			if result != tt.expected {
+				t.Errorf("isIA() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasMetricFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		logGroup types.LogGroup
+		expected bool
+	}{
+		{
+			name: "Log group with metric filters",
+			logGroup: types.LogGroup{
+				MetricFilterCount: aws.Int32(2),
+			},
+			expected: true,
+		},
+		{
+			name: "Log group with no metric filters",
+			logGroup: types.LogGroup{
+				MetricFilterCount: aws.Int32(0),
+			},
+			expected: false,
+		},
+		{
+			name:     "Log group with nil metric filter count",
+			logGroup: types.LogGroup{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := hasMetricFilter(tt.logGroup)
+			if result != tt.expected {
+				t.Errorf("hasMetricFilter() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasDataProtectionPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		logGroup types.LogGroup
+		expected bool
+	}{
+		{
+			name: "Log group with activated data protection",
+			logGroup: types.LogGroup{
+				DataProtectionStatus: types.DataProtectionStatusActivated,
+			},
+			expected: true,
+		},
+		{
+			name: "Log group with no data protection",
+			logGroup: types.LogGroup{
+				// No DataProtectionStatus set
+			},
+			expected: false,
+		},
+		{
+			name:     "Log group with no data protection status",
+			logGroup: types.LogGroup{},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := hasDataProtectionPolicy(tt.logGroup)
+			if result != tt.expected {
+				t.Errorf("hasDataProtectionPolicy() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFetchIndexPoliciesForBatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		batch          []string
+		mockResponse   *cloudwatchlogs.DescribeFieldIndexesOutput
+		mockError      error
+		expectedResult []string
+		expectError    bool
+	}{
+		{
+			name:  "No log groups have index policies",
+			batch: []string{"log1", "log2", "log3"},
+			mockResponse: &cloudwatchlogs.DescribeFieldIndexesOutput{
+				FieldIndexes: []types.FieldIndex{},
+			},
+			mockError:      nil,
+			expectedResult: []string{"log1", "log2", "log3"},
+		},
+		{
+			name:  "Some log groups have index policies",
+			batch: []string{"log1", "log2", "log3"},
+			mockResponse: &cloudwatchlogs.DescribeFieldIndexesOutput{
+				FieldIndexes: []types.FieldIndex{
+					{
+						LogGroupIdentifier: aws.String("log1"),
+					},
+				},
+			},
+			mockError:      nil,
+			expectedResult: []string{"log2", "log3"},
+		},
+		{
+			name:  "All log groups have index policies",
+			batch: []string{"log1", "log2"},
+			mockResponse: &cloudwatchlogs.DescribeFieldIndexesOutput{
+				FieldIndexes: []types.FieldIndex{
+					{
+						LogGroupIdentifier: aws.String("log1"),
+					},
+					{
+						LogGroupIdentifier: aws.String("log2"),
+					},
+				},
+			},
+			mockError:      nil,
+			expectedResult: []string{},
+		},
+		{
+			name:           "API error",
+			batch:          []string{"log1", "log2"},
+			mockError:      errors.New("ThrottlingException"),
+			expectedResult: []string{"log1", "log2"},
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create mock client
+			mockClient := &mockCloudWatchLogsClient{
+				describeFieldIndexesOutput: tt.mockResponse,
+				describeFieldIndexesErr:    tt.mockError,
+			}
+
+			indexed, err := fetchIndexPoliciesForBatch(context.Background(), tt.batch, mockClient)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("fetchIndexPoliciesForBatch() error = %v, expectError %v", err, tt.expectError)
+			}
+			var result []string
+			for _, logGroup := range tt.batch {
+				if _, ok := indexed[logGroup]; !ok {
+					result = append(result, logGroup)
+				}
+			}
+
+			// Sort both slices to ensure consistent comparison
+			if !reflect.DeepEqual(result, tt.expectedResult) {
+				// Special case for empty slices
+				if len(result) == 0 && len(tt.expectedResult) == 0 {
+					// Both are empty, so they're equal
+					return
+				}
+				t.Errorf("fetchIndexPoliciesForBatch() = %v, want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestGetFilteredLogListConcurrently(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockResponse   *cloudwatchlogs.DescribeSubscriptionFiltersOutput
+		mockError      error
+		expectedStatus VerdictStatus
+	}{
+		{
+			name: "No subscription filters",
+			mockResponse: &cloudwatchlogs.DescribeSubscriptionFiltersOutput{
+				SubscriptionFilters: []types.SubscriptionFilter{},
+			},
+			expectedStatus: StatusEligible,
+		},
+		{
+			name: "Subscription filter found",
+			mockResponse: &cloudwatchlogs.DescribeSubscriptionFiltersOutput{
+				SubscriptionFilters: []types.SubscriptionFilter{
+					{
+						FilterName:     aws.String("to-firehose"),
+						DestinationArn: aws.String("arn:aws:firehose:us-west-2:123456789012:deliverystream/logs"),
+					},
+				},
+			},
+			expectedStatus: StatusIneligible,
+		},
+		{
+			name:           "API error leaves the verdict unknown",
+			mockError:      errors.New("AccessDeniedException"),
+			expectedStatus: StatusUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockCloudWatchLogsClient{
+				describeSubscriptionFiltersOutput: tt.mockResponse,
+				describeSubscriptionFiltersErr:    tt.mockError,
+			}
+
+			verdicts := verdictsFromNames("log1")
+			findings := getFilteredLogListConcurrently(context.Background(), NewEngine(DefaultMaxConcurrency, 0), verdicts, mockClient)
+			applyFindings(CheckSubscriptionFilter, verdicts, findings)
+
+			if status := verdicts[0].Status(); status != tt.expectedStatus {
+				t.Errorf("getFilteredLogListConcurrently() status = %v, want %v", status, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestGetTransformers(t *testing.T) {
+	parseJSON := []types.Processor{
+		{ParseJSON: &types.ParseJSON{}},
+		{AddKeys: &types.AddKeys{Entries: []types.AddKeyEntry{{Key: aws.String("env"), Value: aws.String("prod")}}}},
+	}
+
+	tests := []struct {
+		name                string
+		mockResponse        *cloudwatchlogs.GetTransformerOutput
+		mockError           error
+		expectedStatus      VerdictStatus
+		expectedDetail      string
+		expectedTransformer []types.Processor
+	}{
+		{
+			name:           "No transformer",
+			mockResponse:   &cloudwatchlogs.GetTransformerOutput{},
+			expectedStatus: StatusEligible,
+		},
+		{
+			name:           "Transformer not found",
+			mockError:      &types.ResourceNotFoundException{Message: aws.String("Transformer does not exist")},
+			expectedStatus: StatusEligible,
+		},
+		{
+			name:                "Transformer found",
+			mockResponse:        &cloudwatchlogs.GetTransformerOutput{TransformerConfig: parseJSON},
+			expectedStatus:      StatusIneligible,
+			expectedDetail:      "transformer processors: parseJSON, addKeys",
+			expectedTransformer: parseJSON,
+		},
+		{
+			name:           "API error leaves the verdict unknown",
+			mockError:      errors.New("AccessDeniedException"),
+			expectedStatus: StatusUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockCloudWatchLogsClient{
+				getTransformerOutput: tt.mockResponse,
+				getTransformerErr:    tt.mockError,
+			}
+
+			verdicts := verdictsFromNames("log1")
+			findings := getTransformers(context.Background(), NewEngine(DefaultMaxConcurrency, 0), verdicts, mockClient)
+			applyFindings(CheckTransformer, verdicts, findings)
+
+			if status := verdicts[0].Status(); status != tt.expectedStatus {
+				t.Errorf("getTransformers() status = %v, want %v", status, tt.expectedStatus)
+			}
+			if tt.expectedDetail != "" && verdicts[0].Reasons[0].Detail != tt.expectedDetail {
+				t.Errorf("getTransformers() detail = %q, want %q", verdicts[0].Reasons[0].Detail, tt.expectedDetail)
+			}
+			if !reflect.DeepEqual(verdicts[0].Transformer, tt.expectedTransformer) {
+				t.Errorf("getTransformers() transformer = %v, want %v", verdicts[0].Transformer, tt.expectedTransformer)
+			}
+		})
+	}
+}
+
+func TestTransformerConfig(t *testing.T) {
+	processors := []types.Processor{
+		{ParseJSON: &types.ParseJSON{}},
+		{AddKeys: &types.AddKeys{Entries: []types.AddKeyEntry{{Key: aws.String("env"), Value: aws.String("prod")}}}},
+	}
+
+	data, err := json.Marshal(transformerConfig(processors))
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	expected := `[{"parseJSON":{}},{"addKeys":{"entries":[{"key":"env","overwriteIfExists":false,"value":"prod"}]}}]`
+	if string(data) != expected {
+		t.Errorf("transformerConfig() = %s, want %s", data, expected)
+	}
+}
+
+func TestGetTagExclusions(t *testing.T) {
+	rules := &Rules{ExcludeTags: map[string]string{"ia-checker": "skip"}}
+	rules.compile()
+
+	tests := []struct {
+		name           string
+		mockResponse   *cloudwatchlogs.ListTagsForResourceOutput
+		mockError      error
+		expectedStatus VerdictStatus
+	}{
+		{
+			name:           "Untagged log group",
+			mockResponse:   &cloudwatchlogs.ListTagsForResourceOutput{},
+			expectedStatus: StatusEligible,
+		},
+		{
+			name: "Excluded tag",
+			mockResponse: &cloudwatchlogs.ListTagsForResourceOutput{
+				Tags: map[string]string{"ia-checker": "skip"},
+			},
+			expectedStatus: StatusIneligible,
+		},
+		{
+			name: "Tag with another value",
+			mockResponse: &cloudwatchlogs.ListTagsForResourceOutput{
+				Tags: map[string]string{"ia-checker": "keep"},
+			},
+			expectedStatus: StatusEligible,
+		},
+		{
+			name:           "API error leaves the verdict unknown",
+			mockError:      errors.New("AccessDeniedException"),
+			expectedStatus: StatusUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockCloudWatchLogsClient{
+				listTagsForResourceOutput: tt.mockResponse,
+				listTagsForResourceErr:    tt.mockError,
+			}
+
+			verdicts := verdictsFromNames("log1")
+			findings := getTagExclusions(context.Background(), NewEngine(DefaultMaxConcurrency, 0), verdicts, mockClient, rules)
+			applyFindings(CheckTag, verdicts, findings)
+
+			if status := verdicts[0].Status(); status != tt.expectedStatus {
+				t.Errorf("getTagExclusions() status = %v, want %v", status, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestGetAllIndexPoliciesError(t *testing.T) {
+	mockClient := &mockCloudWatchLogsClient{describeFieldIndexesErr: errors.New("ThrottlingException")}
+
+	verdicts := verdictsFromNames("log1", "log2")
+	findings := getAllIndexPolicies(context.Background(), verdicts, mockClient)
+	applyFindings(CheckFieldIndex, verdicts, findings)
+
+	// A failed batch must not make its log groups look unindexed
+	for _, v := range verdicts {
+		if status := v.Status(); status != StatusUnknown {
+			t.Errorf("%s status = %v, want %v", v.LogGroupName, status, StatusUnknown)
+		}
+	}
+}
+
+func TestFindAllLogAnomalyDetectors(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockResponse   *cloudwatchlogs.ListLogAnomalyDetectorsOutput
+		mockError      error
+		expectedStatus VerdictStatus
+	}{
+		{
+			name:           "No anomaly detectors",
+			mockResponse:   &cloudwatchlogs.ListLogAnomalyDetectorsOutput{},
+			expectedStatus: StatusEligible,
+		},
+		{
+			name: "Anomaly detector watches the log group",
+			mockResponse: &cloudwatchlogs.ListLogAnomalyDetectorsOutput{
+				AnomalyDetectors: []types.AnomalyDetector{
+					{
+						DetectorName:    aws.String("detector"),
+						LogGroupArnList: []string{"arn:aws:logs:us-west-2:123456789012:log-group:log1"},
+					},
+				},
+			},
+			expectedStatus: StatusIneligible,
+		},
+		{
+			name:           "API error leaves the verdict unknown",
+			mockError:      errors.New("AccessDeniedException"),
+			expectedStatus: StatusUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &CheckEnv{
+				Rules: DefaultRules(),
+				Logs: &mockCloudWatchLogsClient{
+					listLogAnomalyDetectorsOutput: tt.mockResponse,
+					listLogAnomalyDetectorsErr:    tt.mockError,
+				},
+			}
+
+			verdicts := verdictsFromNames("log1")
+			runChecksOnBatch(context.Background(), env, checksNamed(CheckAnomalyDetector), verdicts)
+
+			if status := verdicts[0].Status(); status != tt.expectedStatus {
+				t.Errorf("anomaly_detector check status = %v, want %v", status, tt.expectedStatus)
+			}
+		})
+	}
+}
+
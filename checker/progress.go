@@ -0,0 +1,270 @@
+// This file contains the progress reporting of a scan: per-stage counters driven by the work that completed, with an ETA.
+package checker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Progress output formats
+const (
+	// Redraw a progress bar line per running stage, for terminals
+	ProgressBar = "bar"
+	// One line per stage start, every 10%, every 10 seconds and completion
+	ProgressPlain = "plain"
+	// The plain events as one JSON object per line
+	ProgressJSON = "json"
+)
+
+// Minimum time between two redraws of the progress bar
+const barRedrawInterval = 100 * time.Millisecond
+
+// Maximum number of bar lines, the running stages beyond it are counted on the last line
+const maxBarLines = 10
+
+// Maximum time between two plain or JSON reports of a running stage, for stages whose total is not known or grows
+const plainReportInterval = 10 * time.Second
+
+// Progress reports how far a scan has got, per region and stage. It is safe for concurrent use by the scanners
+// of every region, and a nil *Progress reports nothing. The bar format draws a line per running stage below the
+// output written through Write, so the log should be routed through it with log.SetOutput.
+type Progress struct {
+	mu       sync.Mutex
+	out      io.Writer
+	format   string
+	now      func() time.Time
+	lastDraw time.Time
+	// Running stages of the bar format in the order they started, and the number of lines drawn for them
+	active []*Stage
+	drawn  int
+}
+
+// Stage counts the completed units of work of one stage of a scan, such as the log groups a check evaluated.
+// A nil *Stage counts nothing.
+type Stage struct {
+	progress *Progress
+	account  string
+	region   string
+	name     string
+	total    int
+	done     int
+	started  time.Time
+	// Last 10% step and time reported by the plain and JSON formats
+	step       int
+	lastReport time.Time
+}
+
+// progressEvent is a line of the JSON format
+type progressEvent struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	Account    string    `json:"account,omitempty"`
+	Region     string    `json:"region"`
+	Stage      string    `json:"stage"`
+	Done       int       `json:"done"`
+	Total      int       `json:"total"`
+	ETASeconds int       `json:"etaSeconds,omitempty"`
+}
+
+// NewProgress returns a Progress that writes to out in the given format
+func NewProgress(out io.Writer, format string) *Progress {
+	return &Progress{out: out, format: format, now: time.Now}
+}
+
+// IsSupportedProgressFormat reports whether format is a progress output format
+func IsSupportedProgressFormat(format string) bool {
+	return format == ProgressBar || format == ProgressPlain || format == ProgressJSON
+}
+
+// IsTerminal reports whether file is a terminal, so the progress bar can be redrawn in place
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Write writes p to the output of the progress, e.g. a log line. The bar format erases the bars first and redraws
+// them below it, so the line does not end up on a half-drawn bar.
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.format != ProgressBar || p.drawn == 0 {
+		return p.out.Write(b)
+	}
+	var out bytes.Buffer
+	p.eraseBars(&out)
+	out.Write(b)
+	p.drawBars(&out, p.now())
+	if _, err := p.out.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Start a stage of total units of work in an account and region. The account is empty for the account of the current
+// credentials. A total of 0 means it is not known in advance.
+func (p *Progress) Start(account, region, name string, total int) *Stage {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := &Stage{progress: p, account: account, region: region, name: name, total: total, started: p.now()}
+	p.report(s, "start")
+	return s
+}
+
+// Expect adds n units of work to the total, for stages whose work arrives while they run
+func (s *Stage) Expect(n int) {
+	if s == nil {
+		return
+	}
+	p := s.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s.total += n
+}
+
+// Add records that n more units of work completed
+func (s *Stage) Add(n int) {
+	if s == nil {
+		return
+	}
+	p := s.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s.done += n
+	if s.total > 0 && s.done > s.total {
+		s.total = s.done
+	}
+	p.report(s, "progress")
+}
+
+// Done records that the stage completed, including the units of work that were skipped
+func (s *Stage) Done() {
+	if s == nil {
+		return
+	}
+	p := s.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s.total > s.done {
+		s.done = s.total
+	}
+	s.total = s.done
+	p.report(s, "done")
+}
+
+// Estimate the time left from the pace so far, 0 when it cannot be estimated yet
+func (s *Stage) eta(now time.Time) time.Duration {
+	if s.done == 0 || s.total <= s.done {
+		return 0
+	}
+	elapsed := now.Sub(s.started)
+	return (elapsed / time.Duration(s.done) * time.Duration(s.total-s.done)).Round(time.Second)
+}
+
+// Write an event of a stage in the format of p. The caller holds p.mu.
+func (p *Progress) report(s *Stage, event string) {
+	now := p.now()
+	switch p.format {
+	case ProgressBar:
+		switch event {
+		case "start":
+			p.active = append(p.active, s)
+		case "progress":
+			if now.Sub(p.lastDraw) < barRedrawInterval {
+				return
+			}
+		case "done":
+			p.active = slices.DeleteFunc(p.active, func(active *Stage) bool { return active == s })
+		}
+		p.lastDraw = now
+		// A completed stage keeps its line above the bars of the running ones
+		var out bytes.Buffer
+		p.eraseBars(&out)
+		if event == "done" {
+			out.WriteString(s.line(now) + "\n")
+		}
+		p.drawBars(&out, now)
+		p.out.Write(out.Bytes())
+	case ProgressPlain, ProgressJSON:
+		// Report the start, every 10% step, a running stage every plainReportInterval and the completion
+		if event == "progress" {
+			step := s.step
+			if s.total > 0 {
+				step = s.done * 10 / s.total
+			}
+			if step <= s.step && now.Sub(s.lastReport) < plainReportInterval {
+				return
+			}
+			s.step = max(s.step, step)
+		}
+		s.lastReport = now
+		if p.format == ProgressJSON {
+			encoded, _ := json.Marshal(progressEvent{
+				Time:       now,
+				Event:      event,
+				Account:    s.account,
+				Region:     s.region,
+				Stage:      s.name,
+				Done:       s.done,
+				Total:      s.total,
+				ETASeconds: int(s.eta(now).Seconds()),
+			})
+			fmt.Fprintln(p.out, string(encoded))
+		} else {
+			fmt.Fprintln(p.out, s.line(now))
+		}
+	}
+}
+
+// Move to the first bar line and erase the bars. The caller holds p.mu.
+func (p *Progress) eraseBars(out *bytes.Buffer) {
+	out.WriteString("\r")
+	if p.drawn > 1 {
+		fmt.Fprintf(out, "\033[%dA", p.drawn-1)
+	}
+	out.WriteString("\033[J")
+	p.drawn = 0
+}
+
+// Draw a bar line per running stage, leaving the cursor at the end of the last one. The caller holds p.mu.
+func (p *Progress) drawBars(out *bytes.Buffer, now time.Time) {
+	for i, s := range p.active {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		if i == maxBarLines-1 && len(p.active) > maxBarLines {
+			fmt.Fprintf(out, "... and %d more stages", len(p.active)-i)
+			p.drawn++
+			break
+		}
+		out.WriteString(s.line(now))
+		p.drawn++
+	}
+}
+
+// Describe the stage on a single line, e.g. [us-east-1] subscription_filter [=====     ] 50.00% (5/10) ETA 3s
+func (s *Stage) line(now time.Time) string {
+	var line strings.Builder
+	if s.region != "" {
+		fmt.Fprintf(&line, "[%s] ", scanLabel(s.account, s.region))
+	}
+	line.WriteString(s.name)
+	if s.total == 0 {
+		fmt.Fprintf(&line, " (%d)", s.done)
+		return line.String()
+	}
+	fmt.Fprintf(&line, " %s", progressBar(s.done, s.total))
+	if eta := s.eta(now); eta > 0 {
+		fmt.Fprintf(&line, " ETA %s", eta)
+	}
+	return line.String()
+}
@@ -0,0 +1,198 @@
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Return a Progress writing to a buffer with a clock that advances a second on every reading
+func newTestProgress(format string) (*Progress, *bytes.Buffer) {
+	var out bytes.Buffer
+	progress := NewProgress(&out, format)
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	progress.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return progress, &out
+}
+
+func TestProgressPlain(t *testing.T) {
+	progress, out := newTestProgress(ProgressPlain)
+
+	stage := progress.Start("", "us-west-2", CheckSubscriptionFilter, 20)
+	for i := 0; i < 10; i++ {
+		stage.Add(1)
+	}
+	stage.Done()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	// The start, one line per 10% step and the completion
+	if len(lines) != 7 {
+		t.Fatalf("got %d lines, want 7:\n%s", len(lines), out.String())
+	}
+	if !strings.HasPrefix(lines[0], "[us-west-2] subscription_filter [") || !strings.HasSuffix(lines[0], "(0/20)") {
+		t.Errorf("unexpected start line: %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[5], "50.00% (10/20) ETA 11s") {
+		t.Errorf("unexpected progress line: %q", lines[5])
+	}
+	// The log groups the check did not need to evaluate are counted on completion
+	if !strings.HasSuffix(lines[6], "100.00% (20/20)") {
+		t.Errorf("unexpected completion line: %q", lines[6])
+	}
+}
+
+func TestProgressPlainOpenStage(t *testing.T) {
+	progress, out := newTestProgress(ProgressPlain)
+
+	// A stage without a known total is reported every plainReportInterval
+	stage := progress.Start("", "us-west-2", "list log groups", 0)
+	for i := 0; i < 12; i++ {
+		stage.Add(50)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || lines[1] != "[us-west-2] list log groups (500)" {
+		t.Errorf("got lines %q, want the start and a line after 10 seconds", lines)
+	}
+}
+
+func TestProgressJSON(t *testing.T) {
+	progress, out := newTestProgress(ProgressJSON)
+
+	stage := progress.Start("123456789012", "us-west-2", "list log groups", 0)
+	stage.Add(50)
+	stage.Add(50)
+	stage.Done()
+
+	var events []progressEvent
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var event progressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid JSON event %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+
+	// Without a total only the start and the completion are reported
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0].Event != "start" || events[0].Account != "123456789012" || events[0].Region != "us-west-2" || events[0].Stage != "list log groups" {
+		t.Errorf("unexpected start event: %+v", events[0])
+	}
+	if events[1].Event != "done" || events[1].Done != 100 || events[1].Total != 100 {
+		t.Errorf("unexpected done event: %+v", events[1])
+	}
+}
+
+func TestProgressBarRedraws(t *testing.T) {
+	progress, out := newTestProgress(ProgressBar)
+
+	stage := progress.Start("", "", CheckTag, 2)
+	stage.Add(1)
+	stage.Done()
+
+	output := out.String()
+	if strings.Count(output, "\r") != 3 {
+		t.Errorf("got %d redraws, want 3: %q", strings.Count(output, "\r"), output)
+	}
+	if !strings.HasSuffix(output, "tag ["+strings.Repeat("=", 50)+"] 100.00% (2/2)\n") {
+		t.Errorf("bar does not end with the completed stage: %q", output)
+	}
+}
+
+func TestProgressBarStages(t *testing.T) {
+	progress, out := newTestProgress(ProgressBar)
+
+	listing := progress.Start("", "us-west-2", "list log groups", 0)
+	tags := progress.Start("", "us-west-2", CheckTag, 2)
+	out.Reset()
+
+	// A log line is written above the bars of the running stages, which are redrawn below it
+	fmt.Fprintln(progress, "Retrieving tags")
+	expected := "\r\033[1A\033[JRetrieving tags\n[us-west-2] list log groups (0)\n[us-west-2] tag [" + strings.Repeat(" ", 50) + "] 0.00% (0/2)"
+	if output := out.String(); output != expected {
+		t.Errorf("log line output = %q, want %q", output, expected)
+	}
+	out.Reset()
+
+	// A completed stage is written above the bars of the stages still running
+	listing.Done()
+	expected = "\r\033[1A\033[J[us-west-2] list log groups (0)\n[us-west-2] tag [" + strings.Repeat(" ", 50) + "] 0.00% (0/2)"
+	if output := out.String(); output != expected {
+		t.Errorf("done output = %q, want %q", output, expected)
+	}
+	out.Reset()
+
+	tags.Done()
+	fmt.Fprintln(progress, "Scan complete")
+	if output := out.String(); !strings.HasSuffix(output, "(2/2)\nScan complete\n") {
+		t.Errorf("output after the last stage = %q, want the log line after the completed stage", output)
+	}
+}
+
+func TestProgressBarMaxLines(t *testing.T) {
+	progress, out := newTestProgress(ProgressBar)
+	for i := 0; i < maxBarLines+2; i++ {
+		progress.Start("", "us-west-2", fmt.Sprintf("check%d", i), 0)
+	}
+
+	lines := strings.Split(out.String()[strings.LastIndex(out.String(), "\033[J")+3:], "\n")
+	if len(lines) != maxBarLines || lines[maxBarLines-1] != "... and 3 more stages" {
+		t.Errorf("got bar lines %q, want %d lines ending with the count of the other stages", lines, maxBarLines)
+	}
+}
+
+func TestProgressPlainAccount(t *testing.T) {
+	progress, out := newTestProgress(ProgressPlain)
+
+	// The scans of the same region in several accounts are told apart by the account
+	progress.Start("123456789012", "us-west-2", "list log groups", 0)
+	if line := strings.TrimSpace(out.String()); line != "[123456789012 us-west-2] list log groups (0)" {
+		t.Errorf("start line = %q, want the account and region", line)
+	}
+}
+
+func TestProgressNil(t *testing.T) {
+	// A scan without progress reporting uses a nil Progress and nil stages
+	var progress *Progress
+	stage := progress.Start("", "us-west-2", CheckTag, 10)
+	stage.Add(1)
+	stage.Done()
+	if stage != nil {
+		t.Errorf("Start() on a nil Progress = %v, want nil", stage)
+	}
+}
+
+func TestStageETA(t *testing.T) {
+	started := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		done     int
+		total    int
+		elapsed  time.Duration
+		expected time.Duration
+	}{
+		{name: "Nothing done", done: 0, total: 10, elapsed: time.Minute, expected: 0},
+		{name: "Unknown total", done: 5, total: 0, elapsed: time.Minute, expected: 0},
+		{name: "Quarter done", done: 25, total: 100, elapsed: time.Minute, expected: 3 * time.Minute},
+		{name: "Complete", done: 10, total: 10, elapsed: time.Minute, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage := &Stage{done: tt.done, total: tt.total, started: started}
+			if eta := stage.eta(started.Add(tt.elapsed)); eta != tt.expected {
+				t.Errorf("eta() = %v, want %v", eta, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// This file contains the helpers for scanning more than one region in a single run.
+package checker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/account"
+	"github.com/aws/aws-sdk-go-v2/service/account/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+// AccountClient is an interface for AWS Account operations
+type AccountClient interface {
+	ListRegions(ctx context.Context, params *account.ListRegionsInput, optFns ...func(*account.Options)) (*account.ListRegionsOutput, error)
+}
+
+// Number of regions that are scanned at the same time
+const maxConcurrentRegions = 4
+
+// Return the regions that are enabled for the account, sorted by name
+func ListEnabledRegions(ctx context.Context, client AccountClient) ([]string, error) {
+	var regions []string
+
+	paginator := account.NewListRegionsPaginator(client, &account.ListRegionsInput{
+		RegionOptStatusContains: []types.RegionOptStatus{
+			types.RegionOptStatusEnabled,
+			types.RegionOptStatusEnabledByDefault,
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, region := range page.Regions {
+			regions = append(regions, aws.ToString(region.RegionName))
+		}
+	}
+
+	sort.Strings(regions)
+	return regions, nil
+}
+
+// Scan every region concurrently, each with its own clients, and merge the verdicts in region order.
+// A region that cannot be scanned is recorded as a failure and does not stop the others.
+func ScanRegions(ctx context.Context, cfg aws.Config, regions []string, options Options) ([]*Verdict, []ScanFailure) {
+	results := make([][]*Verdict, len(regions))
+	failures := scanRegions(ctx, cfg, regions, options, func(index int, v *Verdict) {
+		results[index] = append(results[index], v)
+		if options.OnVerdict != nil {
+			options.OnVerdict(v)
+		}
+	})
+
+	var verdicts []*Verdict
+	for _, result := range results {
+		// An interrupted region keeps the verdicts of the log groups it listed, with the checks it missed as unknown
+		verdicts = append(verdicts, result...)
+	}
+	return verdicts, failures
+}
+
+// StreamRegions is ScanRegions for runs too large to hold every verdict in memory. The verdicts are only passed to
+// options.OnVerdict as soon as they are done, which the regions call concurrently, and none are kept.
+func StreamRegions(ctx context.Context, cfg aws.Config, regions []string, options Options) []ScanFailure {
+	return scanRegions(ctx, cfg, regions, options, func(index int, v *Verdict) {
+		if options.OnVerdict != nil {
+			options.OnVerdict(v)
+		}
+	})
+}
+
+// Stream every region concurrently, calling emit with the index of the region and each of its verdicts. emit is
+// called from a single goroutine per region. Returns the regions that could not be scanned.
+func scanRegions(ctx context.Context, cfg aws.Config, regions []string, options Options, emit func(index int, v *Verdict)) []ScanFailure {
+	eligible := make([]int, len(regions))
+	errs := make([]error, len(regions))
+	sem := make(chan struct{}, maxConcurrentRegions)
+	var wg sync.WaitGroup
+
+	for i, region := range regions {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(index int, region string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			errs[index] = callRecovered(func() error {
+				regionCfg := cfg.Copy()
+				regionCfg.Region = region
+				regionOptions := options
+				regionOptions.Region = region
+				regionOptions.Metrics = cloudwatch.NewFromConfig(regionCfg)
+				scanner := NewScanner(cloudwatchlogs.NewFromConfig(regionCfg), cloudtrail.NewFromConfig(regionCfg), regionOptions)
+				return scanner.Stream(ctx, func(v *Verdict) {
+					if v.Status() == StatusEligible {
+						eligible[index]++
+					}
+					emit(index, v)
+				})
+			})
+		}(i, region)
+	}
+	wg.Wait()
+
+	var failures []ScanFailure
+	for i, err := range errs {
+		label := scanLabel(options.Account, regions[i])
+		// An interrupted region is not a failure, its log groups are reported and the run is marked as incomplete
+		if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			log.Printf("[%s] Scan interrupted: %v", label, err)
+			continue
+		}
+		if err != nil {
+			log.Printf("[%s] Region could not be scanned: %v", label, err)
+			failures = append(failures, ScanFailure{Account: options.Account, Region: regions[i], Error: err.Error()})
+			continue
+		}
+		log.Printf("[%s] Logs that should be considered for transition to IA: %d", label, eligible[i])
+	}
+	return failures
+}
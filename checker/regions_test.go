@@ -0,0 +1,63 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/account"
+	"github.com/aws/aws-sdk-go-v2/service/account/types"
+)
+
+func TestScanRegionsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cfg := aws.Config{Region: "us-west-2", Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")}
+	// An interrupted scan is reported as incomplete, not as regions that failed
+	if _, failures := ScanRegions(ctx, cfg, []string{"us-east-1", "us-west-2"}, Options{}); len(failures) != 0 {
+		t.Errorf("ScanRegions() failures = %+v, want none", failures)
+	}
+}
+
+// Mock Account client for testing
+type mockAccountClient struct {
+	listRegionsOutput *account.ListRegionsOutput
+	listRegionsErr    error
+	input             *account.ListRegionsInput
+}
+
+func (m *mockAccountClient) ListRegions(ctx context.Context, params *account.ListRegionsInput, optFns ...func(*account.Options)) (*account.ListRegionsOutput, error) {
+	m.input = params
+	return m.listRegionsOutput, m.listRegionsErr
+}
+
+func TestListEnabledRegions(t *testing.T) {
+	mockClient := &mockAccountClient{
+		listRegionsOutput: &account.ListRegionsOutput{
+			Regions: []types.Region{
+				{RegionName: aws.String("us-west-2")},
+				{RegionName: aws.String("eu-west-1")},
+			},
+		},
+	}
+
+	regions, err := ListEnabledRegions(context.Background(), mockClient)
+	if err != nil {
+		t.Fatalf("ListEnabledRegions() error = %v", err)
+	}
+	if !reflect.DeepEqual(regions, []string{"eu-west-1", "us-west-2"}) {
+		t.Errorf("ListEnabledRegions() = %v, want [eu-west-1 us-west-2]", regions)
+	}
+	if len(mockClient.input.RegionOptStatusContains) != 2 {
+		t.Errorf("ListRegions filter = %v, want enabled regions only", mockClient.input.RegionOptStatusContains)
+	}
+
+	mockClient.listRegionsErr = errors.New("AccessDenied")
+	if _, err := ListEnabledRegions(context.Background(), mockClient); err == nil {
+		t.Errorf("ListEnabledRegions() expected an error")
+	}
+}
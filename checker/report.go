@@ -0,0 +1,306 @@
+// This file contains the structured report writers used for machine readable output.
+package checker
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Supported values for the -format flag
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+	FormatXLSX   = "xlsx"
+	FormatHTML   = "html"
+)
+
+// RunInfo is the metadata describing a single run of the checker
+type RunInfo struct {
+	Accounts       []string  `json:"accounts"`
+	Regions        []string  `json:"regions"`
+	GeneratedAt    time.Time `json:"generatedAt"`
+	WindowStart    time.Time `json:"windowStart"`
+	WindowEnd      time.Time `json:"windowEnd"`
+	ChecksExecuted []string  `json:"checksExecuted"`
+
+	// Projected savings of all candidates in USD
+	TotalMonthlySavings float64 `json:"totalMonthlySavings"`
+	TotalAnnualSavings  float64 `json:"totalAnnualSavings"`
+
+	// Number of log groups that could not be fully evaluated because a check failed
+	Undetermined int `json:"undetermined"`
+	// Number of CloudTrail events that were skipped because they could not be parsed
+	MalformedTrailEvents int `json:"malformedTrailEvents"`
+	// Version of the catalog of AWS-managed log groups, with the version of the catalog file if one extended it
+	CatalogVersion string `json:"catalogVersion,omitempty"`
+
+	// Set when the scan was interrupted or timed out. The log groups that were not fully checked are unknown.
+	Incomplete       bool   `json:"incomplete"`
+	IncompleteReason string `json:"incompleteReason,omitempty"`
+}
+
+// Report is everything an output writer needs: the run metadata, a verdict per log group
+// and the accounts that could not be scanned
+type Report struct {
+	Run      RunInfo
+	Verdicts []*Verdict
+	Failures []ScanFailure
+}
+
+// logGroupRecord is the structured representation of a verdict
+type logGroupRecord struct {
+	RecordType      string   `json:"recordType,omitempty"`
+	Account         string   `json:"account"`
+	Region          string   `json:"region"`
+	LogGroupName    string   `json:"logGroupName"`
+	LogGroupArn     string   `json:"logGroupArn"`
+	LogGroupClass   string   `json:"logGroupClass"`
+	RetentionInDays int32    `json:"retentionInDays"`
+	StoredBytes     int64    `json:"storedBytes"`
+	CreationTime    string   `json:"creationTime"`
+	Status          string   `json:"status"`
+	ChecksPassed    []string `json:"checksPassed"`
+	ChecksFailed    []Reason `json:"checksFailed"`
+	ChecksUnknown   []Reason `json:"checksUnknown,omitempty"`
+	Notes           []Reason `json:"notes,omitempty"`
+	// Most recent calls of Standard-only APIs and every principal that made them
+	Usages     []Usage          `json:"usages,omitempty"`
+	Principals []PrincipalUsage `json:"principals,omitempty"`
+	// Configuration of the transformer of the log group
+	Transformer []map[string]interface{} `json:"transformer,omitempty"`
+
+	EstimatedMonthlyIngestionBytes float64 `json:"estimatedMonthlyIngestionBytes"`
+	IngestionSource                string  `json:"ingestionSource,omitempty"`
+	EstimatedMonthlySavings        float64 `json:"estimatedMonthlySavings"`
+	EstimatedAnnualSavings         float64 `json:"estimatedAnnualSavings"`
+}
+
+// undeterminedRecord is a log group that could not be fully evaluated, with the checks that failed and their errors
+type undeterminedRecord struct {
+	Account      string   `json:"account"`
+	Region       string   `json:"region"`
+	LogGroupName string   `json:"logGroupName"`
+	Checks       []Reason `json:"checks"`
+}
+
+// Return the log groups whose status is unknown
+func undeterminedRecords(verdicts []*Verdict) []undeterminedRecord {
+	records := []undeterminedRecord{}
+	for _, v := range verdicts {
+		if v.Status() == StatusUnknown {
+			records = append(records, undeterminedRecord{Account: v.Account, Region: v.Region, LogGroupName: v.LogGroupName, Checks: v.Unknown})
+		}
+	}
+	return records
+}
+
+func newLogGroupRecord(v *Verdict) logGroupRecord {
+	record := logGroupRecord{
+		Account:         v.Account,
+		Region:          v.Region,
+		LogGroupName:    v.LogGroupName,
+		LogGroupArn:     v.LogGroupArn,
+		LogGroupClass:   v.LogGroupClass,
+		RetentionInDays: v.RetentionInDays,
+		StoredBytes:     v.StoredBytes,
+		Status:          string(v.Status()),
+		ChecksPassed:    v.Passed,
+		ChecksFailed:    v.Reasons,
+		ChecksUnknown:   v.Unknown,
+		Notes:           v.Notes,
+		Usages:          v.Usages,
+		Principals:      v.Principals,
+		Transformer:     transformerConfig(v.Transformer),
+
+		EstimatedMonthlyIngestionBytes: v.MonthlyIngestionBytes,
+		IngestionSource:                v.IngestionSource,
+		EstimatedMonthlySavings:        v.MonthlySavings,
+		EstimatedAnnualSavings:         v.annualSavings(),
+	}
+	if v.CreationTime > 0 {
+		record.CreationTime = time.UnixMilli(v.CreationTime).UTC().Format(time.RFC3339)
+	}
+	// Emit empty arrays rather than null so consumers don't need to special case them
+	if record.ChecksPassed == nil {
+		record.ChecksPassed = []string{}
+	}
+	if record.ChecksFailed == nil {
+		record.ChecksFailed = []Reason{}
+	}
+	return record
+}
+
+// Build the run metadata from the verdicts. The accounts are taken from the verdicts so no extra call is needed.
+func NewRunInfo(regions []string, now time.Time, rules *Rules, verdicts []*Verdict) RunInfo {
+	summary := NewSummary(0)
+	for _, v := range verdicts {
+		summary.Add(v)
+	}
+	return summary.RunInfo(regions, now, rules)
+}
+
+// Return true if the writer knows the format
+func IsSupportedFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case FormatText, FormatJSON, FormatNDJSON, FormatCSV, FormatXLSX, FormatHTML:
+		return true
+	}
+	return false
+}
+
+// Return the default output file name for a format, e.g. ia.json
+func DefaultOutfile(format string) string {
+	format = strings.ToLower(format)
+	if format == FormatText {
+		return "ia.txt"
+	}
+	return "ia." + format
+}
+
+// Write the report in the requested format
+func WriteReport(fileName, format string, report *Report) error {
+	switch strings.ToLower(format) {
+	case FormatText:
+		return writeToFile(fileName, textLines(report))
+	case FormatJSON:
+		return writeJSONReport(fileName, report)
+	case FormatNDJSON:
+		return writeNDJSONReport(fileName, report)
+	case FormatCSV:
+		return writeCSVReport(fileName, report)
+	case FormatXLSX:
+		return writeXLSXReport(fileName, report)
+	case FormatHTML:
+		return writeHTMLReport(fileName, report)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// Return the eligible log group names for the text output. When the report covers more than one account or region
+// each name is prefixed with them, as the same name can exist in several accounts and regions.
+// An incomplete report starts with a comment line saying so.
+func textLines(report *Report) []string {
+	var lines []string
+	if report.Run.Incomplete {
+		lines = append(lines, "# incomplete scan: "+report.Run.IncompleteReason)
+	}
+
+	multiAccount := len(report.Run.Accounts) > 1
+	multiRegion := len(report.Run.Regions) > 1
+	if !multiAccount && !multiRegion {
+		return append(lines, EligibleNames(report.Verdicts)...)
+	}
+
+	for _, v := range report.Verdicts {
+		if v.Status() == StatusEligible {
+			lines = append(lines, textLine(v, multiAccount, multiRegion))
+		}
+	}
+	return lines
+}
+
+// Return the line of an eligible log group in the text output, prefixed with its account and region if asked
+func textLine(v *Verdict, multiAccount, multiRegion bool) string {
+	line := v.LogGroupName
+	if multiRegion {
+		line = v.Region + "\t" + line
+	}
+	if multiAccount {
+		line = v.Account + "\t" + line
+	}
+	return line
+}
+
+// Write a single JSON document holding the run metadata and an array of log groups
+func writeJSONReport(fileName string, report *Report) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records := make([]logGroupRecord, 0, len(report.Verdicts))
+	for _, v := range report.Verdicts {
+		records = append(records, newLogGroupRecord(v))
+	}
+
+	failures := report.Failures
+	if failures == nil {
+		failures = []ScanFailure{}
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Run          RunInfo              `json:"run"`
+		LogGroups    []logGroupRecord     `json:"logGroups"`
+		Undetermined []undeterminedRecord `json:"undetermined"`
+		Principals   []principalRecord    `json:"principals"`
+		Failures     []ScanFailure        `json:"failures"`
+	}{report.Run, records, undeterminedRecords(report.Verdicts), principalRecords(report.Verdicts), failures})
+}
+
+// Write one JSON object per line. The first line is the run metadata header, the rest are log groups
+// followed by the principals that used Standard-only APIs and the accounts that could not be scanned.
+func writeNDJSONReport(fileName string, report *Report) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	err = encoder.Encode(struct {
+		RecordType string `json:"recordType"`
+		RunInfo
+	}{"run", report.Run})
+	if err != nil {
+		return err
+	}
+
+	for _, v := range report.Verdicts {
+		record := newLogGroupRecord(v)
+		record.RecordType = "logGroup"
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	if err := writeNDJSONTrailer(encoder, principalRecords(report.Verdicts), report.Failures); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// Write the principal and failure records that end an NDJSON report
+func writeNDJSONTrailer(encoder *json.Encoder, principals []principalRecord, failures []ScanFailure) error {
+	for _, principal := range principals {
+		err := encoder.Encode(struct {
+			RecordType string `json:"recordType"`
+			principalRecord
+		}{"principal", principal})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, failure := range failures {
+		err := encoder.Encode(struct {
+			RecordType string `json:"recordType"`
+			ScanFailure
+		}{"failure", failure})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// This file contains the report writer for runs too large to hold every verdict in memory. The text and ndjson
+// formats are written a log group at a time while the scan runs, and only the aggregates of the run metadata and
+// the closing sections of the report are kept.
+package checker
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Summary holds the aggregates of the verdicts of a run that the run metadata and the summary of the CLI need,
+// without holding on to the verdicts themselves. It is safe for concurrent use.
+type Summary struct {
+	mu           sync.Mutex
+	accounts     []string
+	seen         map[string]bool
+	eligible     int
+	undetermined int
+	savings      float64
+	// The candidates with the largest savings, at most top of them, the largest first
+	top        int
+	candidates []*Verdict
+}
+
+// NewSummary returns an empty Summary that keeps the top candidates by savings
+func NewSummary(top int) *Summary {
+	return &Summary{top: top, seen: make(map[string]bool)}
+}
+
+// Add a verdict to the aggregates
+func (s *Summary) Add(v *Verdict) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v.Account != "" && !s.seen[v.Account] {
+		s.seen[v.Account] = true
+		s.accounts = append(s.accounts, v.Account)
+	}
+	switch v.Status() {
+	case StatusUnknown:
+		s.undetermined++
+	case StatusEligible:
+		s.eligible++
+		s.savings += v.MonthlySavings
+		// Keep the candidates sorted, a candidate ties after the ones added before it
+		i := sort.Search(len(s.candidates), func(i int) bool { return s.candidates[i].MonthlySavings < v.MonthlySavings })
+		if i < s.top {
+			s.candidates = append(s.candidates[:i], append([]*Verdict{v}, s.candidates[i:]...)...)
+			if len(s.candidates) > s.top {
+				s.candidates = s.candidates[:s.top]
+			}
+		}
+	}
+}
+
+// Eligible returns the number of candidates
+func (s *Summary) Eligible() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.eligible
+}
+
+// TotalSavings returns the monthly savings of all candidates
+func (s *Summary) TotalSavings() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.savings
+}
+
+// TopCandidates returns the candidates with the largest savings, the largest first
+func (s *Summary) TopCandidates() []*Verdict {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Verdict{}, s.candidates...)
+}
+
+// RunInfo returns the metadata of the run the verdicts were added from
+func (s *Summary) RunInfo(regions []string, now time.Time, rules *Rules) RunInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	windowStart, windowEnd := lookbackWindow(now, rules.windowDays())
+	return RunInfo{
+		Accounts:            append([]string{}, s.accounts...),
+		Regions:             regions,
+		GeneratedAt:         now.UTC(),
+		WindowStart:         windowStart.UTC(),
+		WindowEnd:           windowEnd.UTC(),
+		ChecksExecuted:      rules.enabledChecks(),
+		TotalMonthlySavings: s.savings,
+		TotalAnnualSavings:  s.savings * 12,
+		Undetermined:        s.undetermined,
+	}
+}
+
+// Return true if the format can be written with a ReportStream
+func IsStreamingFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case FormatText, FormatNDJSON:
+		return true
+	}
+	return false
+}
+
+// ReportStream writes a report in the text or ndjson format as the verdicts arrive. The log groups are written to a
+// temporary file next to the report while the scan runs, and Close writes the report with the run metadata both
+// formats start with, followed by the log groups and, for ndjson, the principals and failures. Only the principals
+// of the log groups are kept in memory. It is safe for concurrent use by the scanners of several regions.
+type ReportStream struct {
+	mu       sync.Mutex
+	fileName string
+	format   string
+	// Prefix the lines of the text format with the account and region
+	multiAccount bool
+	multiRegion  bool
+
+	spool   *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+	// The log groups with principals, with nothing else kept, for the principal records of ndjson
+	principals []*Verdict
+	err        error
+}
+
+// NewReportStream returns a ReportStream that writes the report to fileName in format, which must be a streaming
+// format. The lines of the text format are prefixed with the account when multiAccount is set and with the region
+// when multiRegion is set.
+func NewReportStream(fileName, format string, multiAccount, multiRegion bool) (*ReportStream, error) {
+	spool, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	writer := bufio.NewWriter(spool)
+	return &ReportStream{
+		fileName:     fileName,
+		format:       strings.ToLower(format),
+		multiAccount: multiAccount,
+		multiRegion:  multiRegion,
+		spool:        spool,
+		writer:       writer,
+		encoder:      json.NewEncoder(writer),
+	}, nil
+}
+
+// Write the log group of a verdict. An error is also returned by Close.
+func (s *ReportStream) Write(v *Verdict) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.format == FormatText {
+		if v.Status() == StatusEligible {
+			_, s.err = s.writer.WriteString(textLine(v, s.multiAccount, s.multiRegion) + "\n")
+		}
+		return s.err
+	}
+
+	record := newLogGroupRecord(v)
+	record.RecordType = "logGroup"
+	s.err = s.encoder.Encode(record)
+	if len(v.Principals) > 0 {
+		s.principals = append(s.principals, &Verdict{Account: v.Account, Region: v.Region, LogGroupName: v.LogGroupName, Principals: v.Principals})
+	}
+	return s.err
+}
+
+// Close writes the report with the run metadata and failures of report, whose verdicts are ignored, and removes
+// the temporary file
+func (s *ReportStream) Close(report *Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer os.Remove(s.spool.Name())
+	defer s.spool.Close()
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	if _, err := s.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	file, err := os.Create(s.fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	if s.format == FormatText {
+		if report.Run.Incomplete {
+			if _, err := writer.WriteString("# incomplete scan: " + report.Run.IncompleteReason + "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.Copy(writer, s.spool); err != nil {
+			return err
+		}
+		return writer.Flush()
+	}
+
+	err = encoder.Encode(struct {
+		RecordType string `json:"recordType"`
+		RunInfo
+	}{"run", report.Run})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(writer, s.spool); err != nil {
+		return err
+	}
+	if err := writeNDJSONTrailer(encoder, principalRecords(s.principals), report.Failures); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
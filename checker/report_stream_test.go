@@ -0,0 +1,108 @@
+package checker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummary(t *testing.T) {
+	verdicts := verdictsFromNames("small", "large", "tie", "rejected", "unknown")
+	verdicts[0].MonthlySavings = 1
+	verdicts[1].MonthlySavings = 10
+	verdicts[2].MonthlySavings = 10
+	verdicts[3].MonthlySavings = 100
+	verdicts[3].exclude(CheckMetricFilter, "1 metric filters")
+	verdicts[4].undetermined(CheckTag, errors.New("AccessDeniedException"))
+
+	summary := NewSummary(2)
+	for _, v := range verdicts {
+		summary.Add(v)
+	}
+
+	var names []string
+	for _, v := range summary.TopCandidates() {
+		names = append(names, v.LogGroupName)
+	}
+	// The candidates with the same savings stay in the order they were added
+	if !reflect.DeepEqual(names, []string{"large", "tie"}) {
+		t.Errorf("TopCandidates() = %v, want [large tie]", names)
+	}
+	if summary.Eligible() != 3 || summary.TotalSavings() != 21 {
+		t.Errorf("Eligible() = %d, TotalSavings() = %v, want 3 and 21", summary.Eligible(), summary.TotalSavings())
+	}
+
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	if run, expected := summary.RunInfo([]string{"us-west-2"}, now, DefaultRules()), NewRunInfo([]string{"us-west-2"}, now, DefaultRules(), verdicts); !reflect.DeepEqual(run, expected) {
+		t.Errorf("RunInfo() = %+v, want %+v", run, expected)
+	}
+}
+
+// Write the report with a ReportStream and return its content
+func streamReport(t *testing.T, fileName, format string, multiAccount, multiRegion bool, report *Report) string {
+	stream, err := NewReportStream(fileName, format, multiAccount, multiRegion)
+	if err != nil {
+		t.Fatalf("NewReportStream() error = %v", err)
+	}
+	for _, v := range report.Verdicts {
+		if err := stream.Write(v); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := stream.Close(&Report{Run: report.Run, Failures: report.Failures}); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	// The temporary file of the log groups is removed
+	if spools, _ := filepath.Glob("." + fileName + ".*.tmp"); len(spools) != 0 {
+		t.Errorf("temporary files left behind: %v", spools)
+	}
+	return string(content)
+}
+
+func TestReportStreamNDJSON(t *testing.T) {
+	report := testReport()
+	report.Verdicts[1].Principals = []PrincipalUsage{{Principal: "alice", Events: 1, LastUsed: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)}}
+	report.Failures = []ScanFailure{{Account: "210987654321", Error: "unable to assume role"}}
+
+	expectedFile := "test_output.ndjson"
+	defer os.Remove(expectedFile)
+	if err := WriteReport(expectedFile, FormatNDJSON, report); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	expected, err := os.ReadFile(expectedFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	streamFile := "test_stream.ndjson"
+	defer os.Remove(streamFile)
+	// The streamed report has the same records as the one written at the end
+	if content := streamReport(t, streamFile, FormatNDJSON, false, false, report); content != string(expected) {
+		t.Errorf("streamed report =\n%s\nwant\n%s", content, expected)
+	}
+}
+
+func TestReportStreamText(t *testing.T) {
+	report := testReport()
+	report.Verdicts[0].Region = "us-east-1"
+	report.Verdicts[1] = verdictsFromNames("log3")[0]
+	report.Run.Regions = []string{"us-east-1", "us-west-2"}
+	report.Run.Incomplete = true
+	report.Run.IncompleteReason = "scan was interrupted"
+
+	streamFile := "test_stream.txt"
+	defer os.Remove(streamFile)
+	content := streamReport(t, streamFile, FormatText, false, true, report)
+	if expected := strings.Join(textLines(report), "\n") + "\n"; content != expected {
+		t.Errorf("streamed report = %q, want %q", content, expected)
+	}
+}
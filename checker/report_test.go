@@ -0,0 +1,197 @@
+package checker
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testReport() *Report {
+	verdicts := verdictsFromNames("log1", "log2")
+	verdicts[0].RetentionInDays = 30
+	verdicts[0].StoredBytes = 2048
+	verdicts[0].CreationTime = 1700000000000
+	verdicts[0].pass(CheckMetricFilter)
+	verdicts[1].pass(CheckMetricFilter)
+	verdicts[1].exclude(CheckLiveTail, "1 StartLiveTail events in the last 30 days")
+
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	return &Report{Run: NewRunInfo([]string{"us-west-2"}, now, DefaultRules(), verdicts), Verdicts: verdicts}
+}
+
+func TestNewRunInfo(t *testing.T) {
+	run := testReport().Run
+
+	if !reflect.DeepEqual(run.Accounts, []string{"123456789012"}) {
+		t.Errorf("Accounts = %v, want [123456789012]", run.Accounts)
+	}
+	if !reflect.DeepEqual(run.Regions, []string{"us-west-2"}) {
+		t.Errorf("Regions = %v, want [us-west-2]", run.Regions)
+	}
+	if expected := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); !run.WindowStart.Equal(expected) {
+		t.Errorf("WindowStart = %v, want %v", run.WindowStart, expected)
+	}
+	if expected := DefaultRules().enabledChecks(); !reflect.DeepEqual(run.ChecksExecuted, expected) {
+		t.Errorf("ChecksExecuted = %v, want %v", run.ChecksExecuted, expected)
+	}
+}
+
+func TestWriteJSONReport(t *testing.T) {
+	tempFile := "test_output.json"
+	defer os.Remove(tempFile)
+
+	if err := WriteReport(tempFile, FormatJSON, testReport()); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	var decoded struct {
+		Run       RunInfo          `json:"run"`
+		LogGroups []logGroupRecord `json:"logGroups"`
+	}
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded.Run.Accounts, []string{"123456789012"}) {
+		t.Errorf("run.accounts = %v, want [123456789012]", decoded.Run.Accounts)
+	}
+	if len(decoded.LogGroups) != 2 {
+		t.Fatalf("got %d log groups, want 2", len(decoded.LogGroups))
+	}
+
+	first := decoded.LogGroups[0]
+	if first.Status != "eligible" || first.CreationTime != "2023-11-14T22:13:20Z" || first.StoredBytes != 2048 {
+		t.Errorf("unexpected first record: %+v", first)
+	}
+	if len(first.ChecksFailed) != 0 || first.ChecksFailed == nil {
+		t.Errorf("checksFailed = %v, want empty array", first.ChecksFailed)
+	}
+
+	second := decoded.LogGroups[1]
+	if second.Status != "ineligible" || len(second.ChecksFailed) != 1 || second.ChecksFailed[0].Check != CheckLiveTail {
+		t.Errorf("unexpected second record: %+v", second)
+	}
+}
+
+func TestWriteJSONReportUndetermined(t *testing.T) {
+	tempFile := "test_output_undetermined.json"
+	defer os.Remove(tempFile)
+
+	report := testReport()
+	report.Verdicts[0].undetermined(CheckFieldIndex, errors.New("ThrottlingException"))
+	report.Run = NewRunInfo([]string{"us-west-2"}, report.Run.GeneratedAt, DefaultRules(), report.Verdicts)
+	if report.Run.Undetermined != 1 {
+		t.Errorf("Undetermined = %d, want 1", report.Run.Undetermined)
+	}
+
+	if err := WriteReport(tempFile, FormatJSON, report); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	content, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	var decoded struct {
+		Undetermined []undeterminedRecord `json:"undetermined"`
+	}
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if len(decoded.Undetermined) != 1 {
+		t.Fatalf("got %d undetermined records, want 1", len(decoded.Undetermined))
+	}
+	record := decoded.Undetermined[0]
+	if record.LogGroupName != "log1" || len(record.Checks) != 1 || record.Checks[0].Check != CheckFieldIndex || record.Checks[0].Detail != "ThrottlingException" {
+		t.Errorf("unexpected undetermined record: %+v", record)
+	}
+}
+
+func TestWriteNDJSONReport(t *testing.T) {
+	tempFile := "test_output.ndjson"
+	defer os.Remove(tempFile)
+
+	report := testReport()
+	report.Failures = []ScanFailure{{Account: "210987654321", Error: "unable to assume role"}}
+	if err := WriteReport(tempFile, FormatNDJSON, report); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	file, err := os.Open(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer file.Close()
+
+	var recordTypes []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Line is not valid JSON: %v", err)
+		}
+		recordTypes = append(recordTypes, line["recordType"].(string))
+	}
+
+	expected := []string{"run", "logGroup", "logGroup", "failure"}
+	if !reflect.DeepEqual(recordTypes, expected) {
+		t.Errorf("record types = %v, want %v", recordTypes, expected)
+	}
+}
+
+func TestWriteReportUnsupportedFormat(t *testing.T) {
+	if err := WriteReport("test_output.txt", "yaml", testReport()); err == nil {
+		t.Errorf("WriteReport() expected an error for an unsupported format")
+	}
+}
+
+func TestDefaultOutfile(t *testing.T) {
+	tests := map[string]string{
+		FormatText:   "ia.txt",
+		FormatJSON:   "ia.json",
+		FormatNDJSON: "ia.ndjson",
+	}
+	for format, expected := range tests {
+		if result := DefaultOutfile(format); result != expected {
+			t.Errorf("DefaultOutfile(%s) = %v, want %v", format, result, expected)
+		}
+	}
+}
+
+func TestTextLines(t *testing.T) {
+	report := testReport()
+	if lines := textLines(report); !reflect.DeepEqual(lines, []string{"log1"}) {
+		t.Errorf("textLines() for one region = %v, want [log1]", lines)
+	}
+
+	report.Run.Regions = []string{"us-east-1", "us-west-2"}
+	report.Verdicts[0].Region = "us-east-1"
+	if lines := textLines(report); !reflect.DeepEqual(lines, []string{"us-east-1\tlog1"}) {
+		t.Errorf("textLines() for two regions = %v, want [us-east-1\tlog1]", lines)
+	}
+
+	report.Run.Accounts = []string{"123456789012", "210987654321"}
+	if lines := textLines(report); !reflect.DeepEqual(lines, []string{"123456789012\tus-east-1\tlog1"}) {
+		t.Errorf("textLines() for two accounts = %v, want [123456789012\tus-east-1\tlog1]", lines)
+	}
+}
+
+func TestTextLinesIncomplete(t *testing.T) {
+	report := testReport()
+	report.Run.Incomplete = true
+	report.Run.IncompleteReason = "scan was interrupted"
+
+	expected := []string{"# incomplete scan: scan was interrupted", "log1"}
+	if lines := textLines(report); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("textLines() = %v, want %v", lines, expected)
+	}
+}
@@ -0,0 +1,298 @@
+// This file contains the rules file that lets each team turn checks on or off, tune lookback windows and exclude log groups by name or tag.
+package checker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxLookupEventsDays is the longest lookback of the CloudTrail checks when the events come from LookupEvents, which
+// only returns the last 90 days of history. Reading a CloudTrail archive has no limit.
+const MaxLookupEventsDays = 90
+
+// CheckRule turns a single check on or off and tunes it
+type CheckRule struct {
+	Enabled      *bool `json:"enabled,omitempty"`
+	LookbackDays int   `json:"lookbackDays,omitempty"`
+	// Report the log groups a CloudTrail check finds without excluding them
+	Informational *bool `json:"informational,omitempty"`
+	// Number of recent events the emf check reads per log group, and the most bytes of them it parses
+	SampleSize  int `json:"sampleSize,omitempty"`
+	SampleBytes int `json:"sampleBytes,omitempty"`
+}
+
+// Rules is the policy a run is evaluated against. The zero value of every field keeps the built-in behaviour.
+type Rules struct {
+	// Per check settings keyed by check name, e.g. "live_tail"
+	Checks map[string]CheckRule `json:"checks,omitempty"`
+	// Only log groups matching one of these glob patterns are considered, * matches any characters including /
+	Include []string `json:"include,omitempty"`
+	// Log groups matching one of these glob patterns are excluded
+	Exclude []string `json:"exclude,omitempty"`
+	// Log groups with one of these tags are excluded. An empty value or * matches any value of the tag.
+	ExcludeTags map[string]string `json:"excludeTags,omitempty"`
+	// Name substrings that mark a log group as used by Lambda or Container Insights, in addition to the AWS-managed
+	// log groups of the catalog
+	InsightsPatterns []string `json:"insightsPatterns,omitempty"`
+	// CloudTrail events of Standard-only APIs to look for in addition to the built-in ones, each runs as its own check
+	TrailEvents []TrailEvent `json:"trailEvents,omitempty"`
+
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// Return the rules used when no rules file is given
+func DefaultRules() *Rules {
+	rules := &Rules{}
+	rules.compile()
+	return rules
+}
+
+// Load and validate a rules file. An empty file name returns the default rules.
+func LoadRules(fileName string) (*Rules, error) {
+	if fileName == "" {
+		return DefaultRules(), nil
+	}
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &Rules{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(rules); err != nil {
+		return nil, fmt.Errorf("parsing rules file %s: %w", fileName, err)
+	}
+	if err := rules.validate(); err != nil {
+		return nil, fmt.Errorf("rules file %s: %w", fileName, err)
+	}
+	rules.compile()
+	return rules, nil
+}
+
+func (r *Rules) validate() error {
+	known := make(map[string]bool)
+	for _, check := range checkNames() {
+		known[check] = true
+	}
+	for i, event := range r.TrailEvents {
+		switch {
+		case event.Check == "":
+			return fmt.Errorf("trailEvents[%d] has no check name", i)
+		case known[event.Check]:
+			return fmt.Errorf("trailEvents[%d]: check %q already exists", i, event.Check)
+		case len(event.EventNames) == 0:
+			return fmt.Errorf("trailEvents[%d] has no eventNames", i)
+		case len(event.LogGroupPaths) == 0:
+			return fmt.Errorf("trailEvents[%d] has no logGroupPaths", i)
+		}
+		for _, path := range event.LogGroupPaths {
+			if path == "" || strings.Contains(path, "..") || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") {
+				return fmt.Errorf("trailEvents[%d]: invalid logGroupPath %q", i, path)
+			}
+		}
+		known[event.Check] = true
+	}
+	trailChecks := make(map[string]bool)
+	for _, event := range r.trailEvents() {
+		trailChecks[event.Check] = true
+	}
+	for check, rule := range r.Checks {
+		if !known[check] {
+			return fmt.Errorf("unknown check %q", check)
+		}
+		if rule.Informational != nil && !trailChecks[check] {
+			return fmt.Errorf("informational is only supported by CloudTrail checks, not %s", check)
+		}
+		if rule.LookbackDays != 0 && !trailChecks[check] {
+			return fmt.Errorf("lookbackDays is only supported by CloudTrail checks, not %s", check)
+		}
+		if rule.LookbackDays < 0 {
+			return fmt.Errorf("lookbackDays for %s must be positive", check)
+		}
+		if (rule.SampleSize != 0 || rule.SampleBytes != 0) && check != CheckEMF {
+			return fmt.Errorf("sampleSize and sampleBytes are only supported by the %s check, not %s", CheckEMF, check)
+		}
+		if rule.SampleSize < 0 || rule.SampleSize > maxEMFSampleSize {
+			return fmt.Errorf("sampleSize for %s must be between 1 and %d", check, maxEMFSampleSize)
+		}
+		if rule.SampleBytes < 0 {
+			return fmt.Errorf("sampleBytes for %s must be positive", check)
+		}
+	}
+	return nil
+}
+
+// ValidateLookback returns an error if a check looks further back than maxDays, e.g. MaxLookupEventsDays when the
+// CloudTrail events come from LookupEvents
+func (r *Rules) ValidateLookback(maxDays int) error {
+	checks := make([]string, 0, len(r.Checks))
+	for check := range r.Checks {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	for _, check := range checks {
+		if r.Checks[check].LookbackDays > maxDays {
+			return fmt.Errorf("lookbackDays for %s must be between 1 and %d, longer lookbacks need a CloudTrail archive", check, maxDays)
+		}
+	}
+	return nil
+}
+
+func (r *Rules) compile() {
+	r.include = compileGlobs(r.Include)
+	r.exclude = compileGlobs(r.Exclude)
+}
+
+// Return true if the check should run. Name and tag checks only run when patterns or tags are configured.
+func (r *Rules) enabled(check string) bool {
+	switch check {
+	case CheckNamePattern:
+		if len(r.Include) == 0 && len(r.Exclude) == 0 {
+			return false
+		}
+	case CheckTag:
+		if len(r.ExcludeTags) == 0 {
+			return false
+		}
+	}
+	if rule, ok := r.Checks[check]; ok && rule.Enabled != nil {
+		return *rule.Enabled
+	}
+	return true
+}
+
+// Return the names of the registered checks and the CloudTrail checks of the rules file, in execution order
+func (r *Rules) checkNames() []string {
+	names := checkNames()
+	for _, event := range r.TrailEvents {
+		names = append(names, event.Check)
+	}
+	return names
+}
+
+// Return the CloudTrail events that are looked for, the built-in ones first
+func (r *Rules) trailEvents() []TrailEvent {
+	return append(append([]TrailEvent{}, defaultTrailEvents...), r.TrailEvents...)
+}
+
+// Return the checks of the CloudTrail events of the rules file
+func (r *Rules) trailChecks() []Check {
+	var checks []Check
+	for _, event := range r.TrailEvents {
+		checks = append(checks, trailEventCheck(event))
+	}
+	return checks
+}
+
+// Return the checks that will run, in execution order
+func (r *Rules) enabledChecks() []string {
+	var checks []string
+	for _, check := range r.checkNames() {
+		if r.enabled(check) {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// Return the CloudTrail lookback window of a check in days
+func (r *Rules) lookbackDays(check string) int {
+	if rule, ok := r.Checks[check]; ok && rule.LookbackDays > 0 {
+		return rule.LookbackDays
+	}
+	return defaultLookbackDays
+}
+
+// Return true if the log groups found by a CloudTrail check are only reported and not excluded
+func (r *Rules) informational(event TrailEvent) bool {
+	if rule, ok := r.Checks[event.Check]; ok && rule.Informational != nil {
+		return *rule.Informational
+	}
+	return event.Informational
+}
+
+// Return the longest lookback window of the enabled CloudTrail checks, used for the run metadata
+func (r *Rules) windowDays() int {
+	days := 0
+	for _, event := range r.trailEvents() {
+		if r.enabled(event.Check) && r.lookbackDays(event.Check) > days {
+			days = r.lookbackDays(event.Check)
+		}
+	}
+	if days == 0 {
+		return defaultLookbackDays
+	}
+	return days
+}
+
+// Return the number of recent events the emf check reads per log group
+func (r *Rules) sampleSize() int {
+	if rule, ok := r.Checks[CheckEMF]; ok && rule.SampleSize > 0 {
+		return rule.SampleSize
+	}
+	return defaultEMFSampleSize
+}
+
+// Return the most bytes of the sampled events the emf check parses per log group
+func (r *Rules) sampleBytes() int {
+	if rule, ok := r.Checks[CheckEMF]; ok && rule.SampleBytes > 0 {
+		return rule.SampleBytes
+	}
+	return defaultEMFSampleBytes
+}
+
+// Return why the name rules exclude a log group, or an empty string if they don't
+func (r *Rules) nameExclusion(logGroupName string) string {
+	for i, pattern := range r.exclude {
+		if pattern.MatchString(logGroupName) {
+			return "name matches exclude pattern " + r.Exclude[i]
+		}
+	}
+	if len(r.include) == 0 {
+		return ""
+	}
+	for _, pattern := range r.include {
+		if pattern.MatchString(logGroupName) {
+			return ""
+		}
+	}
+	return "name does not match any include pattern"
+}
+
+// Return why the tag rules exclude a log group with the given tags, or an empty string if they don't. The tags are
+// looked at in the order of their keys, so the reason is the same on every run.
+func (r *Rules) tagExclusion(tags map[string]string) string {
+	keys := make([]string, 0, len(r.ExcludeTags))
+	for key := range r.ExcludeTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := r.ExcludeTags[key]
+		tagValue, ok := tags[key]
+		if ok && (value == "" || value == "*" || value == tagValue) {
+			return fmt.Sprintf("tagged %s=%s", key, tagValue)
+		}
+	}
+	return ""
+}
+
+// Compile glob patterns where * matches any characters, including /, and ? matches a single character
+func compileGlobs(patterns []string) []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		expr := regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		compiled = append(compiled, regexp.MustCompile("^"+expr+"$"))
+	}
+	return compiled
+}
@@ -0,0 +1,217 @@
+package checker
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:    "Valid rules",
+			content: `{"checks": {"live_tail": {"lookbackDays": 90}, "anomaly_detector": {"enabled": false}}, "exclude": ["/aws/lambda/*"], "excludeTags": {"team": "payments"}}`,
+		},
+		{
+			name:        "Unknown check",
+			content:     `{"checks": {"live_trail": {"enabled": false}}}`,
+			expectedErr: `unknown check "live_trail"`,
+		},
+		{
+			name:        "Unknown field",
+			content:     `{"excludes": ["/aws/lambda/*"]}`,
+			expectedErr: `unknown field "excludes"`,
+		},
+		{
+			name:        "Negative lookback",
+			content:     `{"checks": {"export_task": {"lookbackDays": -1}}}`,
+			expectedErr: "lookbackDays for export_task must be positive",
+		},
+		{
+			name:    "Trail event with its own lookback",
+			content: `{"trailEvents": [{"check": "insights_query", "eventNames": ["StartQuery"], "logGroupPaths": ["logGroupIdentifiers"], "informational": true}], "checks": {"insights_query": {"lookbackDays": 60}}}`,
+		},
+		{
+			name:        "Trail event named after a registered check",
+			content:     `{"trailEvents": [{"check": "live_tail", "eventNames": ["StartLiveTail"], "logGroupPaths": ["logGroupIdentifiers"]}]}`,
+			expectedErr: `trailEvents[0]: check "live_tail" already exists`,
+		},
+		{
+			name:        "Lookback on a check that is not a CloudTrail check",
+			content:     `{"checks": {"metric_filter": {"lookbackDays": 30}}}`,
+			expectedErr: "lookbackDays is only supported by CloudTrail checks, not metric_filter",
+		},
+		{
+			name:        "Informational on a check that is not a CloudTrail check",
+			content:     `{"checks": {"tag": {"informational": true}}}`,
+			expectedErr: "informational is only supported by CloudTrail checks, not tag",
+		},
+		{
+			name:    "EMF sample size and byte budget",
+			content: `{"checks": {"emf": {"sampleSize": 500, "sampleBytes": 1048576}}}`,
+		},
+		{
+			name:        "Sample size on a check that does not sample",
+			content:     `{"checks": {"subscription_filter": {"sampleSize": 10}}}`,
+			expectedErr: "sampleSize and sampleBytes are only supported by the emf check, not subscription_filter",
+		},
+		{
+			name:        "Sample size beyond FilterLogEvents",
+			content:     `{"checks": {"emf": {"sampleSize": 20000}}}`,
+			expectedErr: "sampleSize for emf must be between 1 and 10000",
+		},
+		{
+			name:        "Trail event without paths",
+			content:     `{"trailEvents": [{"check": "insights_query", "eventNames": ["StartQuery"]}]}`,
+			expectedErr: "trailEvents[0] has no logGroupPaths",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempFile := "test_rules.json"
+			defer os.Remove(tempFile)
+			if err := os.WriteFile(tempFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write rules file: %v", err)
+			}
+
+			_, err := LoadRules(tempFile)
+			if tt.expectedErr == "" && err != nil {
+				t.Errorf("LoadRules() unexpected error: %v", err)
+			}
+			if tt.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expectedErr)) {
+				t.Errorf("LoadRules() error = %v, want %q", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateLookback(t *testing.T) {
+	rules := &Rules{Checks: map[string]CheckRule{CheckLiveTail: {LookbackDays: 30}, CheckExportTask: {LookbackDays: 120}}}
+
+	// LookupEvents only has 90 days of history, an archive has as much as was kept
+	err := rules.ValidateLookback(MaxLookupEventsDays)
+	if err == nil || !strings.Contains(err.Error(), "lookbackDays for export_task must be between 1 and 90") {
+		t.Errorf("ValidateLookback(%d) error = %v, want export_task beyond 90 days", MaxLookupEventsDays, err)
+	}
+	if err := rules.ValidateLookback(365); err != nil {
+		t.Errorf("ValidateLookback(365) unexpected error: %v", err)
+	}
+}
+
+func TestLoadRulesDefault(t *testing.T) {
+	rules, err := LoadRules("")
+	if err != nil {
+		t.Fatalf("LoadRules() unexpected error: %v", err)
+	}
+
+	// Without a rules file the name and tag checks have nothing to do
+	var expected []string
+	for _, check := range checkNames() {
+		if check != CheckNamePattern && check != CheckTag {
+			expected = append(expected, check)
+		}
+	}
+	if checks := rules.enabledChecks(); !reflect.DeepEqual(checks, expected) {
+		t.Errorf("enabledChecks() = %v, want %v", checks, expected)
+	}
+	if days := rules.lookbackDays(CheckLiveTail); days != defaultLookbackDays {
+		t.Errorf("lookbackDays() = %d, want %d", days, defaultLookbackDays)
+	}
+	if size, budget := rules.sampleSize(), rules.sampleBytes(); size != defaultEMFSampleSize || budget != defaultEMFSampleBytes {
+		t.Errorf("sampleSize(), sampleBytes() = %d, %d, want %d, %d", size, budget, defaultEMFSampleSize, defaultEMFSampleBytes)
+	}
+}
+
+func TestRulesEnabled(t *testing.T) {
+	disabled := false
+	rules := &Rules{
+		Checks: map[string]CheckRule{
+			CheckAnomalyDetector: {Enabled: &disabled},
+			CheckExportTask:      {LookbackDays: 7},
+		},
+		Exclude: []string{"/aws/lambda/*"},
+	}
+	rules.compile()
+
+	if rules.enabled(CheckAnomalyDetector) {
+		t.Error("enabled(anomaly_detector) = true, want false")
+	}
+	if !rules.enabled(CheckNamePattern) {
+		t.Error("enabled(name_pattern) = false, want true when patterns are configured")
+	}
+	if rules.enabled(CheckTag) {
+		t.Error("enabled(tag) = true, want false without tags")
+	}
+	if days := rules.lookbackDays(CheckExportTask); days != 7 {
+		t.Errorf("lookbackDays(export_task) = %d, want 7", days)
+	}
+	if days := rules.windowDays(); days != defaultLookbackDays {
+		t.Errorf("windowDays() = %d, want %d", days, defaultLookbackDays)
+	}
+}
+
+func TestNameExclusion(t *testing.T) {
+	rules := &Rules{
+		Include: []string{"/app/*", "/aws/lambda/orders-?"},
+		Exclude: []string{"/app/*/audit"},
+	}
+	rules.compile()
+
+	tests := []struct {
+		logGroupName string
+		excluded     bool
+	}{
+		{"/app/orders", false},
+		{"/app/orders/debug", false},
+		{"/app/orders/audit", true},
+		{"/aws/lambda/orders-1", false},
+		{"/aws/lambda/orders-10", true},
+		{"/other/orders", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.logGroupName, func(t *testing.T) {
+			detail := rules.nameExclusion(tt.logGroupName)
+			if (detail != "") != tt.excluded {
+				t.Errorf("nameExclusion(%q) = %q, want excluded %v", tt.logGroupName, detail, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestTagExclusion(t *testing.T) {
+	rules := &Rules{ExcludeTags: map[string]string{"team": "payments", "compliance": "*"}}
+
+	tests := []struct {
+		name     string
+		tags     map[string]string
+		excluded bool
+	}{
+		{"No tags", nil, false},
+		{"Matching value", map[string]string{"team": "payments"}, true},
+		{"Other value", map[string]string{"team": "search"}, false},
+		{"Any value", map[string]string{"compliance": "pci"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail := rules.tagExclusion(tt.tags)
+			if (detail != "") != tt.excluded {
+				t.Errorf("tagExclusion(%v) = %q, want excluded %v", tt.tags, detail, tt.excluded)
+			}
+		})
+	}
+
+	// With several matching tags the reason names the first key
+	for i := 0; i < 10; i++ {
+		if detail := rules.tagExclusion(map[string]string{"team": "payments", "compliance": "pci"}); detail != "tagged compliance=pci" {
+			t.Fatalf("tagExclusion() = %q, want tagged compliance=pci", detail)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// This file contains the estimate of what moving a log group to Infrequent Access would save.
+package checker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// CloudWatchClient is an interface for CloudWatch metric operations
+type CloudWatchClient interface {
+	GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+// Where the monthly ingestion of a log group was taken from
+const (
+	sourceIncomingBytes = "IncomingBytes"
+	sourceStoredBytes   = "StoredBytes"
+)
+
+const bytesPerGB = 1024 * 1024 * 1024
+
+// GetMetricData accepts at most 500 queries per call
+const metricQueriesPerCall = 500
+
+// IngestionPrice is the CloudWatch Logs ingestion price in USD per GB for each log class
+type IngestionPrice struct {
+	Standard         float64 `json:"standard"`
+	InfrequentAccess float64 `json:"infrequentAccess"`
+}
+
+// Ingestion list prices per region at the time of writing. Regions that are missing fall back to us-east-1,
+// and any region can be overridden with the -pricing file.
+var defaultPricing = map[string]IngestionPrice{
+	"us-east-1":      {Standard: 0.50, InfrequentAccess: 0.25},
+	"us-east-2":      {Standard: 0.50, InfrequentAccess: 0.25},
+	"us-west-1":      {Standard: 0.50, InfrequentAccess: 0.25},
+	"us-west-2":      {Standard: 0.50, InfrequentAccess: 0.25},
+	"ca-central-1":   {Standard: 0.55, InfrequentAccess: 0.275},
+	"eu-west-1":      {Standard: 0.57, InfrequentAccess: 0.285},
+	"eu-west-2":      {Standard: 0.5985, InfrequentAccess: 0.29925},
+	"eu-central-1":   {Standard: 0.63, InfrequentAccess: 0.315},
+	"ap-south-1":     {Standard: 0.50, InfrequentAccess: 0.25},
+	"ap-southeast-1": {Standard: 0.70, InfrequentAccess: 0.35},
+	"ap-southeast-2": {Standard: 0.75, InfrequentAccess: 0.375},
+	"ap-northeast-1": {Standard: 0.76, InfrequentAccess: 0.38},
+	"sa-east-1":      {Standard: 0.90, InfrequentAccess: 0.45},
+}
+
+// Load the pricing table, applying the overrides from a JSON file of the form {"us-east-1": {"standard": 0.5, "infrequentAccess": 0.25}}
+func LoadPricing(fileName string) (map[string]IngestionPrice, error) {
+	pricing := make(map[string]IngestionPrice, len(defaultPricing))
+	for region, price := range defaultPricing {
+		pricing[region] = price
+	}
+	if fileName == "" {
+		return pricing, nil
+	}
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var overrides map[string]IngestionPrice
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&overrides); err != nil {
+		return nil, fmt.Errorf("parsing pricing file %s: %w", fileName, err)
+	}
+
+	// A missing or misspelled price would be 0 and inflate the savings, so every price must be set
+	regions := make([]string, 0, len(overrides))
+	for region := range overrides {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		price := overrides[region]
+		switch {
+		case price.Standard <= 0 || price.InfrequentAccess <= 0:
+			return nil, fmt.Errorf("pricing file %s: %s must have positive standard and infrequentAccess prices", fileName, region)
+		case price.InfrequentAccess >= price.Standard:
+			return nil, fmt.Errorf("pricing file %s: %s infrequentAccess price must be lower than the standard price", fileName, region)
+		}
+		pricing[region] = price
+	}
+	return pricing, nil
+}
+
+// Return the price for a region, falling back to us-east-1 if the region is not in the table
+func priceForRegion(pricing map[string]IngestionPrice, region string) IngestionPrice {
+	if price, ok := pricing[region]; ok {
+		return price
+	}
+	log.Printf("No ingestion price for %s, using us-east-1 prices", region)
+	return pricing["us-east-1"]
+}
+
+// Estimate the monthly ingestion of every log group from StoredBytes and what it would save per month as IA.
+// Groups that are already IA have nothing left to save.
+func estimateSavings(verdicts []*Verdict, price IngestionPrice, now time.Time) {
+	for _, v := range verdicts {
+		v.MonthlyIngestionBytes = estimateMonthlyIngestion(v, now)
+		v.IngestionSource = sourceStoredBytes
+		v.MonthlySavings = monthlySavings(v, price)
+	}
+}
+
+// Replace the StoredBytes estimate with the IncomingBytes metric for the log groups it was found for
+func applyIncomingBytes(verdicts []*Verdict, incoming map[string]float64, price IngestionPrice) {
+	for _, v := range verdicts {
+		if bytes, ok := incoming[v.LogGroupName]; ok {
+			v.MonthlyIngestionBytes = bytes
+			v.IngestionSource = sourceIncomingBytes
+			v.MonthlySavings = monthlySavings(v, price)
+		}
+	}
+}
+
+// Rough estimate of the bytes ingested per month. StoredBytes holds the data of the last
+// min(retention, age) days, so spread it over that many days and scale to 30 days.
+func estimateMonthlyIngestion(v *Verdict, now time.Time) float64 {
+	if v.StoredBytes <= 0 {
+		return 0
+	}
+
+	days := float64(v.RetentionInDays)
+	if v.CreationTime > 0 {
+		age := now.Sub(time.UnixMilli(v.CreationTime)).Hours() / 24
+		if days == 0 || age < days {
+			days = age
+		}
+	}
+	if days < 1 {
+		days = 1
+	}
+
+	return float64(v.StoredBytes) / days * 30
+}
+
+// Return the monthly saving in USD of ingesting the log group as IA instead of Standard
+func monthlySavings(v *Verdict, price IngestionPrice) float64 {
+	if v.LogGroupClass == "INFREQUENT_ACCESS" {
+		return 0
+	}
+	return v.MonthlyIngestionBytes / bytesPerGB * (price.Standard - price.InfrequentAccess)
+}
+
+// Sum the IncomingBytes metric of each log group over the 30 days before now, keyed by log group name.
+// Log groups without any datapoints are left out so they keep the StoredBytes estimate.
+func getIncomingBytes(ctx context.Context, verdicts []*Verdict, client CloudWatchClient, now time.Time) (map[string]float64, error) {
+	incoming := make(map[string]float64)
+	startTime := now.AddDate(0, 0, -30)
+
+	for i := 0; i < len(verdicts); i += metricQueriesPerCall {
+		end := i + metricQueriesPerCall
+		if end > len(verdicts) {
+			end = len(verdicts)
+		}
+
+		// One query per log group, the id maps the result back to the log group
+		ids := make(map[string]string)
+		var queries []cwtypes.MetricDataQuery
+		for j, v := range verdicts[i:end] {
+			id := fmt.Sprintf("m%d", j)
+			ids[id] = v.LogGroupName
+			queries = append(queries, cwtypes.MetricDataQuery{
+				Id: aws.String(id),
+				MetricStat: &cwtypes.MetricStat{
+					Metric: &cwtypes.Metric{
+						Namespace:  aws.String("AWS/Logs"),
+						MetricName: aws.String("IncomingBytes"),
+						Dimensions: []cwtypes.Dimension{
+							{Name: aws.String("LogGroupName"), Value: aws.String(v.LogGroupName)},
+						},
+					},
+					Period: aws.Int32(86400),
+					Stat:   aws.String("Sum"),
+				},
+			})
+		}
+
+		paginator := cloudwatch.NewGetMetricDataPaginator(client, &cloudwatch.GetMetricDataInput{
+			StartTime:         &startTime,
+			EndTime:           &now,
+			MetricDataQueries: queries,
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return incoming, err
+			}
+			for _, result := range page.MetricDataResults {
+				name, ok := ids[aws.ToString(result.Id)]
+				if !ok || len(result.Values) == 0 {
+					continue
+				}
+				for _, value := range result.Values {
+					incoming[name] += value
+				}
+			}
+		}
+	}
+
+	return incoming, nil
+}
+
+// Return the candidates ordered by monthly savings, largest first
+func RankBySavings(verdicts []*Verdict) []*Verdict {
+	var candidates []*Verdict
+	for _, v := range verdicts {
+		if v.Status() == StatusEligible {
+			candidates = append(candidates, v)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].MonthlySavings > candidates[j].MonthlySavings
+	})
+	return candidates
+}
+
+// Return the monthly savings of all candidates
+func TotalSavings(verdicts []*Verdict) float64 {
+	total := 0.0
+	for _, v := range verdicts {
+		if v.Status() == StatusEligible {
+			total += v.MonthlySavings
+		}
+	}
+	return total
+}
+
+// Log the top candidates by monthly savings
+func LogTopCandidates(verdicts []*Verdict, top int) {
+	ranked := RankBySavings(verdicts)
+	if len(ranked) > top {
+		ranked = ranked[:top]
+	}
+
+	log.Printf("Top %d candidates by projected savings:", len(ranked))
+	for i, v := range ranked {
+		log.Printf("%3d. %-60s $%s/month $%s/year (%s)", i+1, v.LogGroupName, FormatMoney(v.MonthlySavings), FormatMoney(v.annualSavings()), v.IngestionSource)
+	}
+}
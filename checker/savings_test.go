@@ -0,0 +1,219 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// Mock CloudWatch client for testing
+type mockCloudWatchClient struct {
+	getMetricDataOutput *cloudwatch.GetMetricDataOutput
+	getMetricDataErr    error
+	calls               int
+}
+
+func (m *mockCloudWatchClient) GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	m.calls++
+	return m.getMetricDataOutput, m.getMetricDataErr
+}
+
+func TestEstimateMonthlyIngestion(t *testing.T) {
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		verdict  *Verdict
+		expected float64
+	}{
+		{
+			name:     "No stored bytes",
+			verdict:  &Verdict{},
+			expected: 0,
+		},
+		{
+			name: "Retention shorter than age",
+			verdict: &Verdict{
+				StoredBytes:     7 * bytesPerGB,
+				RetentionInDays: 7,
+				CreationTime:    now.AddDate(-1, 0, 0).UnixMilli(),
+			},
+			expected: 30 * bytesPerGB,
+		},
+		{
+			name: "Never expire uses the age of the log group",
+			verdict: &Verdict{
+				StoredBytes:  60 * bytesPerGB,
+				CreationTime: now.AddDate(0, 0, -60).UnixMilli(),
+			},
+			expected: 30 * bytesPerGB,
+		},
+		{
+			name: "Log group younger than a day",
+			verdict: &Verdict{
+				StoredBytes:  bytesPerGB,
+				CreationTime: now.Add(-time.Hour).UnixMilli(),
+			},
+			expected: 30 * bytesPerGB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := estimateMonthlyIngestion(tt.verdict, now)
+			if math.Abs(result-tt.expected) > 1 {
+				t.Errorf("estimateMonthlyIngestion() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEstimateSavings(t *testing.T) {
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	verdicts := []*Verdict{
+		{LogGroupName: "standard", LogGroupClass: "STANDARD", StoredBytes: 30 * bytesPerGB, RetentionInDays: 30},
+		{LogGroupName: "ia", LogGroupClass: "INFREQUENT_ACCESS", StoredBytes: 30 * bytesPerGB, RetentionInDays: 30},
+	}
+
+	estimateSavings(verdicts, defaultPricing["us-east-1"], now)
+
+	if verdicts[0].MonthlySavings != 7.5 {
+		t.Errorf("MonthlySavings = %v, want 7.5", verdicts[0].MonthlySavings)
+	}
+	if verdicts[1].MonthlySavings != 0 {
+		t.Errorf("MonthlySavings for IA log group = %v, want 0", verdicts[1].MonthlySavings)
+	}
+}
+
+func TestGetIncomingBytes(t *testing.T) {
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	verdicts := verdictsFromNames("log1", "log2")
+
+	mockClient := &mockCloudWatchClient{
+		getMetricDataOutput: &cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []cwtypes.MetricDataResult{
+				{Id: aws.String("m0"), Values: []float64{100, 200}},
+				{Id: aws.String("m1"), Values: []float64{}},
+			},
+		},
+	}
+
+	incoming, err := getIncomingBytes(context.Background(), verdicts, mockClient, now)
+	if err != nil {
+		t.Fatalf("getIncomingBytes() error = %v", err)
+	}
+	if !reflect.DeepEqual(incoming, map[string]float64{"log1": 300}) {
+		t.Errorf("getIncomingBytes() = %v, want map[log1:300]", incoming)
+	}
+
+	applyIncomingBytes(verdicts, incoming, defaultPricing["us-east-1"])
+	if verdicts[0].IngestionSource != sourceIncomingBytes || verdicts[0].MonthlyIngestionBytes != 300 {
+		t.Errorf("log1 = %+v, want IncomingBytes estimate of 300", verdicts[0])
+	}
+	if verdicts[1].IngestionSource != "" {
+		t.Errorf("log2 source = %v, want unchanged", verdicts[1].IngestionSource)
+	}
+}
+
+func TestGetIncomingBytesBatches(t *testing.T) {
+	var names []string
+	for i := 0; i < metricQueriesPerCall+1; i++ {
+		names = append(names, "log")
+	}
+	mockClient := &mockCloudWatchClient{getMetricDataOutput: &cloudwatch.GetMetricDataOutput{}}
+
+	if _, err := getIncomingBytes(context.Background(), verdictsFromNames(names...), mockClient, time.Now()); err != nil {
+		t.Fatalf("getIncomingBytes() error = %v", err)
+	}
+	if mockClient.calls != 2 {
+		t.Errorf("GetMetricData called %d times, want 2", mockClient.calls)
+	}
+}
+
+func TestGetIncomingBytesError(t *testing.T) {
+	mockClient := &mockCloudWatchClient{getMetricDataErr: errors.New("AccessDenied")}
+	if _, err := getIncomingBytes(context.Background(), verdictsFromNames("log1"), mockClient, time.Now()); err == nil {
+		t.Errorf("getIncomingBytes() expected an error")
+	}
+}
+
+func TestLoadPricing(t *testing.T) {
+	tempFile := "test_pricing.json"
+	defer os.Remove(tempFile)
+	content := `{"us-east-1": {"standard": 0.4, "infrequentAccess": 0.2}, "xx-test-1": {"standard": 1, "infrequentAccess": 0.5}}`
+	if err := os.WriteFile(tempFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write pricing file: %v", err)
+	}
+
+	pricing, err := LoadPricing(tempFile)
+	if err != nil {
+		t.Fatalf("LoadPricing() error = %v", err)
+	}
+	if price := priceForRegion(pricing, "us-east-1"); price.Standard != 0.4 {
+		t.Errorf("us-east-1 standard price = %v, want the override 0.4", price.Standard)
+	}
+	if price := priceForRegion(pricing, "xx-test-1"); price.InfrequentAccess != 0.5 {
+		t.Errorf("xx-test-1 IA price = %v, want 0.5", price.InfrequentAccess)
+	}
+	if price := priceForRegion(pricing, "eu-central-1"); price != defaultPricing["eu-central-1"] {
+		t.Errorf("eu-central-1 price = %v, want the default", price)
+	}
+	if price := priceForRegion(pricing, "unknown-region-1"); price.Standard != 0.4 {
+		t.Errorf("unknown region price = %v, want the us-east-1 fallback", price)
+	}
+	if defaultPricing["us-east-1"].Standard != 0.50 {
+		t.Errorf("LoadPricing() modified the default pricing table")
+	}
+}
+
+func TestLoadPricingInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "Unknown field", content: `{"eu-north-1": {"standard": 0.5, "ia": 0.25}}`},
+		{name: "Zero price", content: `{"eu-north-1": {"standard": 0.5, "infrequentAccess": 0}}`},
+		{name: "Negative price", content: `{"eu-north-1": {"standard": -0.5, "infrequentAccess": 0.25}}`},
+		{name: "IA not cheaper", content: `{"eu-north-1": {"standard": 0.5, "infrequentAccess": 0.5}}`},
+	}
+
+	tempFile := "test_pricing.json"
+	defer os.Remove(tempFile)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(tempFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write pricing file: %v", err)
+			}
+			if _, err := LoadPricing(tempFile); err == nil {
+				t.Errorf("LoadPricing() expected an error")
+			}
+		})
+	}
+}
+
+func TestRankBySavings(t *testing.T) {
+	verdicts := []*Verdict{
+		{LogGroupName: "small", MonthlySavings: 1},
+		{LogGroupName: "rejected", MonthlySavings: 100, Reasons: []Reason{{Check: CheckMetricFilter}}},
+		{LogGroupName: "large", MonthlySavings: 10},
+	}
+
+	var names []string
+	for _, v := range RankBySavings(verdicts) {
+		names = append(names, v.LogGroupName)
+	}
+	if !reflect.DeepEqual(names, []string{"large", "small"}) {
+		t.Errorf("RankBySavings() = %v, want [large small]", names)
+	}
+	if total := TotalSavings(verdicts); total != 11 {
+		t.Errorf("TotalSavings() = %v, want 11", total)
+	}
+}
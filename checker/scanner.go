@@ -0,0 +1,190 @@
+// This file contains the Scanner, the entry point for running every check against the log groups of one account and region.
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// Options tunes a scan. The zero value scans with the default rules and prices.
+type Options struct {
+	// Rules to evaluate the log groups against, DefaultRules() when nil
+	Rules *Rules
+	// Ingestion prices per region, the built-in table when nil
+	Pricing map[string]IngestionPrice
+	// AWS-managed log group patterns of the insights check, the built-in catalog when nil
+	Catalog *Catalog
+	// End of the lookback windows, time.Now() when zero
+	Now time.Time
+	// Account the clients are for, set by ScanAccounts. Empty when it is the account of the current credentials,
+	// which the verdicts take from the ARNs of the log groups.
+	Account string
+	// Region the clients are for. It is recorded on every verdict and picks the price.
+	Region string
+	// Optional client for the IncomingBytes metric. Without it the savings are estimated from StoredBytes.
+	Metrics CloudWatchClient
+	// Checks to run in order, the registered checks and the CloudTrail checks of the rules when nil
+	Checks []Check
+	// Limit of concurrent per log group API calls, DefaultMaxConcurrency when 0
+	MaxConcurrency int
+	// Limit of calls per second to each API, DefaultRequestsPerSecond when 0
+	RequestsPerSecond float64
+	// Where to report the progress of the scan, nothing is reported when nil
+	Progress *Progress
+	// Counts the CloudTrail events that could not be used, nothing is counted when nil
+	TrailStats *TrailStats
+	// Directory of CloudTrail log files synced from the S3 bucket of a trail. When set the CloudTrail checks read it
+	// instead of calling LookupEvents, which allows lookbacks beyond MaxLookupEventsDays.
+	TrailArchive string
+	// CloudTrail Lake event data store the CloudTrail checks query instead of calling LookupEvents, shared by the
+	// scanners of every account and region. It takes precedence over TrailArchive and allows lookbacks beyond
+	// MaxLookupEventsDays.
+	TrailLake *TrailLake
+	// Optional function Scan calls with every verdict as soon as it is done, e.g. to write it out before the scan
+	// ends. The scanners of several regions call it concurrently.
+	OnVerdict func(*Verdict)
+}
+
+// Return the prefix of the log lines and progress stages of the scan of a region, preceded by the account when it
+// is set, e.g. "123456789012 us-east-1"
+func scanLabel(account, region string) string {
+	if account == "" {
+		return region
+	}
+	return account + " " + region
+}
+
+// Scanner runs the checks against the log groups of a single account and region
+type Scanner struct {
+	logs    CloudWatchLogsClient
+	trail   CloudTrailClient
+	options Options
+}
+
+// NewScanner returns a Scanner that uses the given clients. The clients must be for the same account and region.
+func NewScanner(logs CloudWatchLogsClient, trail CloudTrailClient, options Options) *Scanner {
+	if options.Rules == nil {
+		options.Rules = DefaultRules()
+	}
+	if options.Pricing == nil {
+		options.Pricing = defaultPricing
+	}
+	if options.MaxConcurrency == 0 {
+		options.MaxConcurrency = DefaultMaxConcurrency
+	}
+	if options.RequestsPerSecond == 0 {
+		options.RequestsPerSecond = DefaultRequestsPerSecond
+	}
+	return &Scanner{logs: logs, trail: trail, options: options}
+}
+
+// Scan lists the log groups, runs every enabled check against them and estimates the savings of the candidates.
+// It returns a verdict for every log group. If ctx is cancelled the verdicts of the log groups listed so far are
+// returned with ctx.Err(), and the checks that did not get to run are recorded on them as undetermined.
+func (s *Scanner) Scan(ctx context.Context) ([]*Verdict, error) {
+	var verdicts []*Verdict
+	err := s.Stream(ctx, func(v *Verdict) {
+		verdicts = append(verdicts, v)
+		if s.options.OnVerdict != nil {
+			s.options.OnVerdict(v)
+		}
+	})
+	return verdicts, err
+}
+
+// Stream is Scan for accounts too large to hold their log groups in memory at once. The pages of log groups flow
+// through the checks as they are listed, and emit is called with every verdict as soon as the checks and the
+// savings estimate are done with it. emit is called from a single goroutine. If ctx is cancelled the log groups
+// listed so far are still emitted, with the checks that did not get to run recorded as undetermined.
+func (s *Scanner) Stream(ctx context.Context, emit func(*Verdict)) error {
+	options := s.options
+	if err := options.Rules.validate(); err != nil {
+		return fmt.Errorf("invalid rules: %w", err)
+	}
+	if options.TrailArchive == "" && options.TrailLake == nil {
+		if err := options.Rules.ValidateLookback(MaxLookupEventsDays); err != nil {
+			return fmt.Errorf("invalid rules: %w", err)
+		}
+	}
+	// Compile a copy, the same rules are shared by the scanners of every region
+	rules := *options.Rules
+	rules.compile()
+	options.Rules = &rules
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	checks := options.Checks
+	if checks == nil {
+		checks = append(append([]Check{}, checkRegistry...), rules.trailChecks()...)
+	}
+
+	// List the log groups a page at a time
+	label := scanLabel(options.Account, options.Region)
+	log.Printf("[%s] Retrieving list of log groups.", label)
+	listing := options.Progress.Start(options.Account, options.Region, "list log groups", 0)
+	pages := make(chan []*Verdict, pipelineBuffer)
+	listed := make(chan error, 1)
+	go func() {
+		listed <- callRecovered(func() error { return getLogList(ctx, s.logs, listing, pages) })
+		listing.Done()
+	}()
+
+	// Run every enabled check against the pages as they arrive
+	checked := runChecks(ctx, &CheckEnv{
+		Account:      options.Account,
+		Region:       options.Region,
+		Now:          now,
+		Rules:        options.Rules,
+		Logs:         s.logs,
+		CloudTrail:   s.trail,
+		Engine:       NewEngine(options.MaxConcurrency, options.RequestsPerSecond),
+		Progress:     options.Progress,
+		TrailStats:   options.TrailStats,
+		TrailArchive: options.TrailArchive,
+		TrailLake:    options.TrailLake,
+		Catalog:      options.Catalog,
+	}, checks, pages)
+
+	// Estimate the ingestion and savings of every log group for the report, using the IncomingBytes metric for the
+	// candidates and StoredBytes for everything else. The candidates of several pages are held back so their
+	// metrics are fetched with as few GetMetricData calls as possible.
+	price := priceForRegion(options.Pricing, options.Region)
+	var pending []*Verdict
+	var candidates int
+	flush := func() {
+		if options.Metrics != nil && candidates > 0 {
+			incoming, err := getIncomingBytes(ctx, RankBySavings(pending), options.Metrics, now)
+			if err != nil {
+				log.Printf("[%s] Error retrieving IncomingBytes metrics, falling back to StoredBytes: %v", label, err)
+			}
+			applyIncomingBytes(pending, incoming, price)
+		}
+		for _, v := range pending {
+			v.Account = accountFromArn(v.LogGroupArn)
+			v.Region = options.Region
+			// The checks are done with the DescribeLogGroups output, only keep what is reported
+			v.logGroup = types.LogGroup{}
+			emit(v)
+		}
+		pending, candidates = nil, 0
+	}
+	for batch := range checked {
+		estimateSavings(batch, price, now)
+		pending = append(pending, batch...)
+		candidates += len(RankBySavings(batch))
+		if candidates >= metricQueriesPerCall {
+			flush()
+		}
+	}
+	flush()
+
+	if listErr := <-listed; listErr != nil && ctx.Err() == nil {
+		return fmt.Errorf("listing log groups: %w", listErr)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,177 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cttypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// Return a logs client with two log groups, one of which has a metric filter, and no other features in use
+func newScannerLogsClient() *mockCloudWatchLogsClient {
+	return &mockCloudWatchLogsClient{
+		describeLogGroupsOutput: &cloudwatchlogs.DescribeLogGroupsOutput{
+			LogGroups: []types.LogGroup{
+				{
+					LogGroupName:      aws.String("with-metric-filter"),
+					LogGroupArn:       aws.String("arn:aws:logs:us-west-2:123456789012:log-group:with-metric-filter"),
+					MetricFilterCount: aws.Int32(1),
+				},
+				{
+					LogGroupName: aws.String("candidate"),
+					LogGroupArn:  aws.String("arn:aws:logs:us-west-2:123456789012:log-group:candidate"),
+					StoredBytes:  aws.Int64(30 * bytesPerGB),
+				},
+			},
+		},
+		describeFieldIndexesOutput:        &cloudwatchlogs.DescribeFieldIndexesOutput{},
+		describeSubscriptionFiltersOutput: &cloudwatchlogs.DescribeSubscriptionFiltersOutput{},
+		listLogAnomalyDetectorsOutput:     &cloudwatchlogs.ListLogAnomalyDetectorsOutput{},
+		getTransformerOutput:              &cloudwatchlogs.GetTransformerOutput{},
+		filterLogEventsOutput:             &cloudwatchlogs.FilterLogEventsOutput{},
+	}
+}
+
+func TestScannerScan(t *testing.T) {
+	trailClient := &mockCloudTrailClient{lookupEventsOutput: &cloudtrail.LookupEventsOutput{Events: []cttypes.Event{}}}
+	scanner := NewScanner(newScannerLogsClient(), trailClient, Options{
+		Region: "us-west-2",
+		Now:    time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+	})
+
+	verdicts, err := scanner.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if len(verdicts) != 2 {
+		t.Fatalf("Scan() returned %d verdicts, want 2", len(verdicts))
+	}
+
+	if status := verdicts[0].Status(); status != StatusIneligible {
+		t.Errorf("with-metric-filter status = %v, want %v", status, StatusIneligible)
+	}
+	candidate := verdicts[1]
+	if status := candidate.Status(); status != StatusEligible {
+		t.Errorf("candidate status = %v, reasons %v unknown %v", status, candidate.Reasons, candidate.Unknown)
+	}
+	if candidate.Account != "123456789012" || candidate.Region != "us-west-2" {
+		t.Errorf("candidate account/region = %s/%s, want 123456789012/us-west-2", candidate.Account, candidate.Region)
+	}
+	if candidate.IngestionSource != sourceStoredBytes || candidate.MonthlySavings <= 0 {
+		t.Errorf("candidate savings = %v from %s, want a StoredBytes estimate", candidate.MonthlySavings, candidate.IngestionSource)
+	}
+}
+
+// pagedLogsClient returns the log groups of newScannerLogsClient one page per log group and counts the calls that
+// cover the whole account
+type pagedLogsClient struct {
+	*mockCloudWatchLogsClient
+	anomalyDetectorCalls int
+}
+
+func (m *pagedLogsClient) DescribeLogGroups(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	logGroups := m.describeLogGroupsOutput.LogGroups
+	page := 0
+	if params.NextToken != nil {
+		page = int(aws.ToString(params.NextToken)[0] - '0')
+	}
+	output := &cloudwatchlogs.DescribeLogGroupsOutput{LogGroups: logGroups[page : page+1]}
+	if page+1 < len(logGroups) {
+		output.NextToken = aws.String(string(rune('0' + page + 1)))
+	}
+	return output, nil
+}
+
+func (m *pagedLogsClient) ListLogAnomalyDetectors(ctx context.Context, params *cloudwatchlogs.ListLogAnomalyDetectorsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListLogAnomalyDetectorsOutput, error) {
+	m.anomalyDetectorCalls++
+	return m.mockCloudWatchLogsClient.ListLogAnomalyDetectors(ctx, params, optFns...)
+}
+
+func TestScannerStream(t *testing.T) {
+	logs := &pagedLogsClient{mockCloudWatchLogsClient: newScannerLogsClient()}
+	trailClient := &mockCloudTrailClient{lookupEventsOutput: &cloudtrail.LookupEventsOutput{Events: []cttypes.Event{}}}
+	scanner := NewScanner(logs, trailClient, Options{Region: "us-west-2"})
+
+	var names []string
+	err := scanner.Stream(context.Background(), func(v *Verdict) {
+		names = append(names, v.LogGroupName)
+		if v.Account != "123456789012" || v.Region != "us-west-2" {
+			t.Errorf("%s account/region = %s/%s, want 123456789012/us-west-2", v.LogGroupName, v.Account, v.Region)
+		}
+		if v.logGroup.LogGroupName != nil {
+			t.Errorf("%s still holds the DescribeLogGroups output", v.LogGroupName)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Stream() unexpected error: %v", err)
+	}
+
+	if expected := []string{"with-metric-filter", "candidate"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Stream() emitted %v, want %v", names, expected)
+	}
+	// Every page goes through the checks on its own, but the anomaly detectors are listed once
+	if logs.anomalyDetectorCalls != 1 {
+		t.Errorf("ListLogAnomalyDetectors was called %d times, want once", logs.anomalyDetectorCalls)
+	}
+}
+
+func TestScannerScanErrors(t *testing.T) {
+	failingClient := newScannerLogsClient()
+	failingClient.describeLogGroupsErr = errors.New("AccessDeniedException")
+
+	tests := []struct {
+		name    string
+		logs    CloudWatchLogsClient
+		options Options
+	}{
+		{
+			name: "DescribeLogGroups fails",
+			logs: failingClient,
+		},
+		{
+			name:    "Invalid rules",
+			logs:    newScannerLogsClient(),
+			options: Options{Rules: &Rules{Checks: map[string]CheckRule{"live_trail": {}}}},
+		},
+		{
+			name:    "Lookback beyond LookupEvents without an archive",
+			logs:    newScannerLogsClient(),
+			options: Options{Rules: &Rules{Checks: map[string]CheckRule{CheckLiveTail: {LookbackDays: 365}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner(tt.logs, &mockCloudTrailClient{}, tt.options)
+			if _, err := scanner.Scan(context.Background()); err == nil {
+				t.Error("Scan() expected an error")
+			}
+		})
+	}
+}
+
+func TestScannerScanCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	scanner := NewScanner(newScannerLogsClient(), &mockCloudTrailClient{}, Options{Region: "us-west-2"})
+	verdicts, err := scanner.Scan(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Scan() error = %v, want %v", err, context.Canceled)
+	}
+
+	// The listed log groups are kept, and the checks that did not run leave them unknown instead of eligible
+	if len(verdicts) != 2 {
+		t.Fatalf("Scan() returned %d verdicts, want 2", len(verdicts))
+	}
+	if status := verdicts[1].Status(); status != StatusUnknown {
+		t.Errorf("candidate status = %v, want %v", status, StatusUnknown)
+	}
+}
@@ -0,0 +1,306 @@
+// This file contains the CSV and XLSX writers for reviewers that work in spreadsheets.
+package checker
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Return the checks that get a column: every registered check and the checks of the rules file that ran
+func reportChecks(report *Report) []string {
+	checks := checkNames()
+	registered := make(map[string]bool, len(checks))
+	for _, check := range checks {
+		registered[check] = true
+	}
+	for _, check := range report.Run.ChecksExecuted {
+		if !registered[check] {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// Header of the log group table, with one column per check
+func spreadsheetHeader(checks []string) []string {
+	header := []string{"Account", "Region", "Log Group Name", "Log Group ARN", "Log Group Class", "Retention (days)", "Stored Bytes", "Creation Time", "Status"}
+	header = append(header, checks...)
+	return append(header, "Estimated Monthly Ingestion (GB)", "Ingestion Source", "Potential Monthly Savings (USD)", "Potential Annual Savings (USD)")
+}
+
+// One row of the log group table. Values are strings or numbers so the XLSX writer can keep numeric cells numeric.
+func spreadsheetRow(v *Verdict, checks []string) []interface{} {
+	creationTime := ""
+	if v.CreationTime > 0 {
+		creationTime = time.UnixMilli(v.CreationTime).UTC().Format(time.RFC3339)
+	}
+
+	row := []interface{}{v.Account, v.Region, v.LogGroupName, v.LogGroupArn, v.LogGroupClass, v.RetentionInDays, v.StoredBytes, creationTime, string(v.Status())}
+	for _, check := range checks {
+		row = append(row, v.checkResult(check))
+	}
+	return append(row, v.MonthlyIngestionBytes/bytesPerGB, v.IngestionSource, v.MonthlySavings, v.annualSavings())
+}
+
+// Write one row per log group as CSV
+func writeCSVReport(fileName string, report *Report) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	checks := reportChecks(report)
+	writer := csv.NewWriter(file)
+	if err := writer.Write(spreadsheetHeader(checks)); err != nil {
+		return err
+	}
+
+	for _, v := range report.Verdicts {
+		var record []string
+		for _, value := range spreadsheetRow(v, checks) {
+			record = append(record, csvValue(value))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvValue(value interface{}) string {
+	switch value := value.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// worksheet is a named sheet of rows for the XLSX writer
+type worksheet struct {
+	name string
+	rows [][]interface{}
+}
+
+// Write a workbook with the log group table and a summary sheet with counts per exclusion reason
+func writeXLSXReport(fileName string, report *Report) error {
+	checks := reportChecks(report)
+	logGroups := worksheet{name: "Log Groups"}
+	header := []interface{}{}
+	for _, column := range spreadsheetHeader(checks) {
+		header = append(header, column)
+	}
+	logGroups.rows = append(logGroups.rows, header)
+	for _, v := range report.Verdicts {
+		logGroups.rows = append(logGroups.rows, spreadsheetRow(v, checks))
+	}
+
+	return writeWorkbook(fileName, []worksheet{logGroups, summarySheet(report), undeterminedSheet(report), principalsSheet(report)})
+}
+
+// Build the sheet of the principals that used Standard-only APIs, one row per principal and log group
+func principalsSheet(report *Report) worksheet {
+	sheet := worksheet{name: "Principals"}
+	sheet.rows = append(sheet.rows, []interface{}{"Principal", "Account", "Region", "Name", "Events", "Last Used"})
+	for _, record := range principalRecords(report.Verdicts) {
+		for _, logGroup := range record.LogGroups {
+			sheet.rows = append(sheet.rows, []interface{}{record.Principal, logGroup.Account, logGroup.Region, logGroup.LogGroupName, logGroup.Events, logGroup.LastUsed.UTC().Format(time.RFC3339)})
+		}
+	}
+	return sheet
+}
+
+// Build the sheet of log groups that could not be fully evaluated, one row per failed check
+func undeterminedSheet(report *Report) worksheet {
+	sheet := worksheet{name: "Undetermined"}
+	sheet.rows = append(sheet.rows, []interface{}{"Account", "Region", "Name", "Check", "Error"})
+	for _, record := range undeterminedRecords(report.Verdicts) {
+		for _, check := range record.Checks {
+			sheet.rows = append(sheet.rows, []interface{}{record.Account, record.Region, record.LogGroupName, check.Check, check.Detail})
+		}
+	}
+	return sheet
+}
+
+// Build the summary sheet: log groups per status, per exclusion reason and the total savings of the candidates
+func summarySheet(report *Report) worksheet {
+	statusCounts := make(map[VerdictStatus]int)
+	reasonCounts := make(map[string]int)
+	for _, v := range report.Verdicts {
+		statusCounts[v.Status()]++
+		for _, reason := range v.Reasons {
+			reasonCounts[reason.Check]++
+		}
+	}
+
+	summary := worksheet{name: "Summary"}
+	summary.rows = append(summary.rows,
+		[]interface{}{"Accounts", strings.Join(report.Run.Accounts, ", ")},
+		[]interface{}{"Regions", strings.Join(report.Run.Regions, ", ")},
+		[]interface{}{"Generated At", report.Run.GeneratedAt.Format(time.RFC3339)},
+	)
+	if report.Run.Incomplete {
+		summary.rows = append(summary.rows, []interface{}{"Incomplete Scan", report.Run.IncompleteReason})
+	}
+	summary.rows = append(summary.rows,
+		[]interface{}{},
+		[]interface{}{"Status", "Log Groups"},
+	)
+	for _, status := range []VerdictStatus{StatusEligible, StatusIneligible, StatusUnknown} {
+		summary.rows = append(summary.rows, []interface{}{string(status), statusCounts[status]})
+	}
+
+	if len(report.Run.Regions) > 1 {
+		summary.rows = append(summary.rows, []interface{}{}, []interface{}{"Region", "Log Groups", "Candidates", "Candidate Monthly Savings (USD)"})
+		for _, region := range report.Run.Regions {
+			total, candidates, savings := 0, 0, 0.0
+			for _, v := range report.Verdicts {
+				if v.Region != region {
+					continue
+				}
+				total++
+				if v.Status() == StatusEligible {
+					candidates++
+					savings += v.MonthlySavings
+				}
+			}
+			summary.rows = append(summary.rows, []interface{}{region, total, candidates, savings})
+		}
+	}
+
+	if len(report.Run.Accounts) > 1 || len(report.Failures) > 0 {
+		summary.rows = append(summary.rows, []interface{}{}, []interface{}{"Account", "Log Groups", "Candidates", "Candidate Monthly Savings (USD)", "Error"})
+		for _, account := range report.Run.Accounts {
+			total, candidates, savings := 0, 0, 0.0
+			for _, v := range report.Verdicts {
+				if v.Account != account {
+					continue
+				}
+				total++
+				if v.Status() == StatusEligible {
+					candidates++
+					savings += v.MonthlySavings
+				}
+			}
+			summary.rows = append(summary.rows, []interface{}{account, total, candidates, savings})
+		}
+		for _, failure := range report.Failures {
+			summary.rows = append(summary.rows, []interface{}{failure.Account, 0, 0, 0.0, failure.Error})
+		}
+	}
+
+	summary.rows = append(summary.rows, []interface{}{}, []interface{}{"Exclusion Reason", "Log Groups"})
+	for _, check := range reportChecks(report) {
+		summary.rows = append(summary.rows, []interface{}{check, reasonCounts[check]})
+	}
+
+	summary.rows = append(summary.rows,
+		[]interface{}{},
+		[]interface{}{"Candidate Monthly Savings (USD)", report.Run.TotalMonthlySavings},
+		[]interface{}{"Candidate Annual Savings (USD)", report.Run.TotalAnnualSavings},
+	)
+	return summary
+}
+
+// Write a minimal Office Open XML workbook. Strings are written inline so no shared string table is needed.
+func writeWorkbook(fileName string, sheets []worksheet) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	var overrides, sheetEntries, relationships strings.Builder
+	for i, sheet := range sheets {
+		overrides.WriteString(fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1))
+		sheetEntries.WriteString(fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.name), i+1, i+1))
+		relationships.WriteString(fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1))
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheetEntries.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			relationships.String() + `</Relationships>`},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheetXML(sheet)})
+	}
+
+	for _, part := range parts {
+		writer, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// Render the rows of a sheet as SpreadsheetML
+func worksheetXML(sheet worksheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range sheet.rows {
+		b.WriteString(fmt.Sprintf(`<row r="%d">`, r+1))
+		for c, value := range row {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			switch value := value.(type) {
+			case int, int32, int64:
+				b.WriteString(fmt.Sprintf(`<c r="%s"><v>%d</v></c>`, ref, value))
+			case float64:
+				b.WriteString(fmt.Sprintf(`<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(value, 'f', -1, 64)))
+			default:
+				b.WriteString(fmt.Sprintf(`<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, xmlEscape(fmt.Sprint(value))))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// Return the spreadsheet column name for a zero based index, e.g. 0 -> A, 26 -> AA
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func xmlEscape(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package checker
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSVReport(t *testing.T) {
+	tempFile := "test_output.csv"
+	defer os.Remove(tempFile)
+
+	report := testReport()
+	report.Verdicts[0].MonthlyIngestionBytes = 2 * bytesPerGB
+	report.Verdicts[0].MonthlySavings = 0.5
+
+	if err := WriteReport(tempFile, FormatCSV, report); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	file, err := os.Open(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d rows, want 3", len(records))
+	}
+
+	header := records[0]
+	column := func(name string) int {
+		for i, value := range header {
+			if value == name {
+				return i
+			}
+		}
+		t.Fatalf("column %q not found in %v", name, header)
+		return -1
+	}
+
+	if value := records[1][column(CheckMetricFilter)]; value != "pass" {
+		t.Errorf("metric_filter for log1 = %q, want pass", value)
+	}
+	if value := records[1][column(CheckLiveTail)]; value != "" {
+		t.Errorf("live_tail for log1 = %q, want empty", value)
+	}
+	if value := records[2][column(CheckLiveTail)]; !strings.HasPrefix(value, "fail: ") {
+		t.Errorf("live_tail for log2 = %q, want a failure", value)
+	}
+	if value := records[1][column("Estimated Monthly Ingestion (GB)")]; value != "2.00" {
+		t.Errorf("ingestion for log1 = %q, want 2.00", value)
+	}
+	if value := records[1][column("Potential Monthly Savings (USD)")]; value != "0.50" {
+		t.Errorf("savings for log1 = %q, want 0.50", value)
+	}
+}
+
+func TestWriteXLSXReport(t *testing.T) {
+	tempFile := "test_output.xlsx"
+	defer os.Remove(tempFile)
+
+	if err := WriteReport(tempFile, FormatXLSX, testReport()); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+
+	archive, err := zip.OpenReader(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to open workbook: %v", err)
+	}
+	defer archive.Close()
+
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		parts[file.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml", "xl/worksheets/sheet3.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook is missing %s", name)
+		}
+	}
+
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Summary"`) {
+		t.Errorf("workbook does not have a Summary sheet")
+	}
+	if !strings.Contains(parts["xl/worksheets/sheet1.xml"], "<t>log2</t>") {
+		t.Errorf("log group sheet does not contain log2")
+	}
+	if !strings.Contains(parts["xl/worksheets/sheet2.xml"], `<t>live_tail</t></is></c><c r="B`) {
+		t.Errorf("summary sheet does not count live_tail")
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Undetermined"`) {
+		t.Errorf("workbook does not have an Undetermined sheet")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for index, expected := range tests {
+		if result := columnName(index); result != expected {
+			t.Errorf("columnName(%d) = %v, want %v", index, result, expected)
+		}
+	}
+}
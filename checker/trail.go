@@ -0,0 +1,335 @@
+// This file is for making CloudTrail calls. Some of the features that are standard only are API calls such as Live Tail,
+// which are detected from a table of CloudTrail events
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+// Default number of days of CloudTrail history that are checked for Standard-only API usage
+const defaultLookbackDays = 30
+
+// Return the start and end of a CloudTrail lookback window of days ending at now
+func lookbackWindow(now time.Time, days int) (time.Time, time.Time) {
+	return now.AddDate(0, 0, -days), now
+}
+
+// CloudTrailClient is an interface for CloudTrail operations
+type CloudTrailClient interface {
+	LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error)
+}
+
+// TrailEvent describes CloudTrail events that show a log group uses a feature of the Standard class.
+// The built-in events are below, more can be added in the rules file.
+type TrailEvent struct {
+	// Name of the check in the reports and the rules file, e.g. "live_tail"
+	Check string `json:"check"`
+	// CloudTrail event names, e.g. "StartLiveTail"
+	EventNames []string `json:"eventNames"`
+	// Paths in requestParameters that hold log group names or ARNs, either a string or an array of strings.
+	// Nested parameters are separated with dots, e.g. "source.logGroupName".
+	LogGroupPaths []string `json:"logGroupPaths"`
+	// Informational events are reported on the log group without making it ineligible
+	Informational bool `json:"informational,omitempty"`
+}
+
+// Built-in CloudTrail events of Standard-only APIs
+var (
+	liveTailEvent = TrailEvent{
+		Check:         CheckLiveTail,
+		EventNames:    []string{"StartLiveTail"},
+		LogGroupPaths: []string{"logGroupIdentifiers"},
+	}
+	exportTaskEvent = TrailEvent{
+		Check:         CheckExportTask,
+		EventNames:    []string{"CreateExportTask"},
+		LogGroupPaths: []string{"logGroupName"},
+	}
+	// Scripts and consoles reading the events of a log group directly rather than through Logs Insights
+	logEventsReadEvent = TrailEvent{
+		Check:         CheckLogEventsRead,
+		EventNames:    []string{"GetLogEvents", "FilterLogEvents"},
+		LogGroupPaths: []string{"logGroupName", "logGroupIdentifier"},
+	}
+	defaultTrailEvents = []TrailEvent{liveTailEvent, exportTaskEvent, logEventsReadEvent}
+)
+
+// Number of principals named in the detail of a finding, the others are only counted
+const maxPrincipalsInDetail = 3
+
+// trailUsage is a log group named in a CloudTrail event
+type trailUsage struct {
+	LogGroupName string
+	// Account the call was made in, empty if the event does not say
+	Account string
+	Usage
+}
+
+// Return the check that looks for the log groups named in the events of a TrailEvent
+func trailEventCheck(event TrailEvent) Check {
+	return accountWideCheck(event.Check,
+		func(ctx context.Context, env *CheckEnv) ([]trailUsage, error) {
+			switch {
+			case env.TrailLake != nil:
+				// The event data store is queried once for all the CloudTrail checks, accounts and regions
+				usages, err := env.TrailLake.regionUsages(ctx, env)
+				return usages[event.Check], err
+			case env.TrailArchive != "":
+				// The archive is read once for all the CloudTrail checks
+				archived, err := env.LoadOnce("cloudtrail archive", func() (any, error) {
+					return readTrailArchive(ctx, env, env.TrailArchive)
+				})
+				if err != nil {
+					return nil, err
+				}
+				return archived.(map[string][]trailUsage)[event.Check], nil
+			}
+			return trailLogGroups(ctx, env.CloudTrail, event, env.Now, env.Rules.lookbackDays(event.Check), env.TrailStats)
+		},
+		func(env *CheckEnv, batch []*Verdict, usages []trailUsage) []Finding {
+			event := event
+			event.Informational = env.Rules.informational(event)
+			return trailFindings(batch, usages, event, env.Rules.lookbackDays(event.Check))
+		})
+}
+
+// Return the log groups named in the events of a TrailEvent in the days before now, once per event and log group.
+// Failed calls and the calls of the checker are skipped, and events that cannot be parsed are logged and counted in
+// stats.
+func trailLogGroups(ctx context.Context, client CloudTrailClient, event TrailEvent, now time.Time, days int, stats *TrailStats) ([]trailUsage, error) {
+	startTime, endTime := lookbackWindow(now, days)
+
+	// List to store the log groups named in the events
+	var usages []trailUsage
+
+	// LookupEvents takes a single event name, so each one is looked up on its own
+	for _, eventName := range event.EventNames {
+		// Create a paginator for LookupEvents
+		paginator := cloudtrail.NewLookupEventsPaginator(client, &cloudtrail.LookupEventsInput{
+			EndTime:   &endTime,
+			StartTime: &startTime,
+			LookupAttributes: []types.LookupAttribute{
+				{
+					AttributeKey:   types.LookupAttributeKeyEventName,
+					AttributeValue: aws.String(eventName),
+				},
+			},
+		})
+
+		// Iterate through pages of events
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("retrieving %s events from CloudTrail: %w", eventName, err)
+			}
+
+			// Process each event in the page
+			for _, lookedUp := range page.Events {
+				record, err := parseTrailRecord(aws.ToString(lookedUp.CloudTrailEvent))
+				if err != nil {
+					log.Printf("Skipping CloudTrail event %s: %v", aws.ToString(lookedUp.EventId), err)
+					stats.addMalformed()
+					continue
+				}
+				if record.EventName != eventName || record.failed() || record.ownCall() {
+					continue
+				}
+
+				// Extract the log group identifiers from the event's requestParameters
+				recordUsages, err := record.trailUsages(event)
+				if err != nil {
+					log.Printf("Skipping CloudTrail event %s: %v", aws.ToString(lookedUp.EventId), err)
+					stats.addMalformed()
+					continue
+				}
+				usages = append(usages, recordUsages...)
+			}
+		}
+	}
+
+	return usages, nil
+}
+
+// trailFilter selects the events the CloudTrail checks look for among the events of every API, for the sources that
+// read them all at once rather than by event name
+type trailFilter struct {
+	// Region of the events, any region when empty
+	region string
+	now    time.Time
+	// TrailEvents by CloudTrail event name
+	events map[string][]TrailEvent
+	// Start of the lookback window of every check
+	starts map[string]time.Time
+}
+
+// Return a filter for the events of the enabled TrailEvents of the rules, in the lookback window of their check
+func newTrailFilter(rules *Rules, now time.Time, region string) *trailFilter {
+	filter := &trailFilter{
+		region: region,
+		now:    now,
+		events: make(map[string][]TrailEvent),
+		starts: make(map[string]time.Time),
+	}
+	for _, event := range rules.trailEvents() {
+		if !rules.enabled(event.Check) {
+			continue
+		}
+		for _, eventName := range event.EventNames {
+			filter.events[eventName] = append(filter.events[eventName], event)
+		}
+		filter.starts[event.Check], _ = lookbackWindow(now, rules.lookbackDays(event.Check))
+	}
+	return filter
+}
+
+// Return the start of the longest lookback window
+func (f *trailFilter) since() time.Time {
+	since := f.now
+	for _, start := range f.starts {
+		if start.Before(since) {
+			since = start
+		}
+	}
+	return since
+}
+
+// Return the names of the events the filter selects, sorted
+func (f *trailFilter) eventNames() []string {
+	names := make([]string, 0, len(f.events))
+	for name := range f.events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Return true if an event of the name and region may be selected, before it is parsed
+func (f *trailFilter) selects(eventName, region string) bool {
+	return len(f.events[eventName]) > 0 && (f.region == "" || region == f.region)
+}
+
+// Add the usages of a parsed event to the usages of every check it is in the lookback window of. Failed calls and
+// the calls of the checker are skipped, and an error is returned if the event does not name its log groups the way its TrailEvent says.
+func (f *trailFilter) add(record *trailRecord, usages map[string][]trailUsage) error {
+	if !f.selects(record.EventName, record.AWSRegion) || record.failed() || record.ownCall() || record.EventTime.After(f.now) {
+		return nil
+	}
+	for _, event := range f.events[record.EventName] {
+		if record.EventTime.Before(f.starts[event.Check]) {
+			continue
+		}
+		recordUsages, err := record.trailUsages(event)
+		if err != nil {
+			return err
+		}
+		usages[event.Check] = append(usages[event.Check], recordUsages...)
+	}
+	return nil
+}
+
+// Return the strings at a dotted path of a parsed JSON object, whether the value is a string or an array of strings.
+// A missing path holds nothing, any other value is an error.
+func stringsAtPath(object map[string]interface{}, path string) ([]string, error) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		switch nested := object[key].(type) {
+		case nil:
+			return nil, nil
+		case map[string]interface{}:
+			object = nested
+		default:
+			return nil, fmt.Errorf("%s is not an object", key)
+		}
+	}
+
+	switch value := object[keys[len(keys)-1]].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("holds a %T in its array", item)
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("holds a %T", value)
+	}
+}
+
+// Count the events per log group and return a finding for every log group that had at least one, naming the
+// event counts and the principals that made the calls
+func trailFindings(verdicts []*Verdict, usages []trailUsage, event TrailEvent, days int) []Finding {
+	byLogGroup := make(map[string][]trailUsage)
+	for _, usage := range usages {
+		byLogGroup[usage.LogGroupName] = append(byLogGroup[usage.LogGroupName], usage)
+	}
+
+	var findings []Finding
+	for _, verdict := range verdicts {
+		// An archive can hold the events of several accounts, which may have log groups of the same name
+		account := accountFromArn(verdict.LogGroupArn)
+		var logGroupUsages []Usage
+		for _, usage := range byLogGroup[verdict.LogGroupName] {
+			if usage.Account == "" || account == "" || usage.Account == account {
+				logGroupUsages = append(logGroupUsages, usage.Usage)
+			}
+		}
+		if len(logGroupUsages) == 0 {
+			continue
+		}
+		counts := make(map[string]int)
+		principals := make(map[string]bool)
+		for _, usage := range logGroupUsages {
+			counts[usage.EventName]++
+			if usage.Principal != "" {
+				principals[usage.Principal] = true
+			}
+		}
+
+		var events []string
+		for _, eventName := range event.EventNames {
+			if count := counts[eventName]; count > 0 {
+				events = append(events, fmt.Sprintf("%d %s", count, eventName))
+			}
+		}
+		detail := fmt.Sprintf("%s events in the last %d days", strings.Join(events, " and "), days)
+		if by := principalSummary(principals); by != "" {
+			detail += " by " + by
+		}
+		findings = append(findings, Finding{
+			LogGroupName:  verdict.LogGroupName,
+			Detail:        detail,
+			Informational: event.Informational,
+			Usages:        logGroupUsages,
+		})
+	}
+	return findings
+}
+
+// List the principals in a stable order, naming at most maxPrincipalsInDetail of them
+func principalSummary(principals map[string]bool) string {
+	names := make([]string, 0, len(principals))
+	for principal := range principals {
+		names = append(names, principal)
+	}
+	sort.Strings(names)
+	if len(names) > maxPrincipalsInDetail {
+		return fmt.Sprintf("%s and %d more", strings.Join(names[:maxPrincipalsInDetail], ", "), len(names)-maxPrincipalsInDetail)
+	}
+	return strings.Join(names, ", ")
+}
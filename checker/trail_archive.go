@@ -0,0 +1,195 @@
+// This file reads the CloudTrail log files of a trail from a local directory, as synced from its S3 bucket, so the
+// CloudTrail checks can look back further than the 90 days of LookupEvents and are not held back by its rate limit.
+package checker
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number of CloudTrail log files decoded at the same time
+var archiveWorkers = runtime.NumCPU()
+
+// Directories a trail writes its log files to, e.g. AWSLogs/123456789012/CloudTrail/us-east-1/2025/01/31
+var archiveDirPattern = regexp.MustCompile(`(?:^|/)CloudTrail/([a-z0-9-]+)(?:/(\d{4})(?:/(\d{2})(?:/(\d{2}))?)?)?$`)
+
+// Directories of the accounts of a trail, e.g. AWSLogs/123456789012 or AWSLogs/o-abc123/123456789012 for an
+// organization trail
+var archiveAccountDirPattern = regexp.MustCompile(`(?:^|/)AWSLogs/(?:o-[a-z0-9]+/)?(\d{12})$`)
+
+// archiveFile is the content of a CloudTrail log file
+type archiveFile struct {
+	Records []json.RawMessage `json:"Records"`
+}
+
+// Read the CloudTrail log files under dir and return the log groups named in the events of every enabled TrailEvent,
+// per check. Only the events of the account and region of env in the lookback window of their check are kept, and
+// the directories of the other accounts of an organization trail are not read. The files are
+// decoded concurrently, and files or events that cannot be parsed are logged and counted in env.TrailStats.
+func readTrailArchive(ctx context.Context, env *CheckEnv, dir string) (map[string][]trailUsage, error) {
+	filter := newTrailFilter(env.Rules, env.Now, env.Region)
+	files, err := archiveFiles(dir, env.Account, env.Region, filter.since())
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[%s] Reading %d CloudTrail log files from %s", scanLabel(env.Account, env.Region), len(files), dir)
+	stage := env.Progress.Start(env.Account, env.Region, "read CloudTrail archive", len(files))
+	defer stage.Done()
+
+	paths := make(chan string)
+	go func() {
+		defer close(paths)
+		for _, path := range files {
+			select {
+			case paths <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Every worker collects the usages of its files, they are merged once all the files are read
+	workers := max(1, min(archiveWorkers, len(files)))
+	results := make(chan map[string][]trailUsage, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			usages := make(map[string][]trailUsage)
+			for path := range paths {
+				err := callRecovered(func() error { return readArchiveFile(filter, path, usages, env.TrailStats) })
+				if err != nil {
+					log.Printf("Skipping CloudTrail log file %s: %v", path, err)
+					env.TrailStats.addMalformed()
+				}
+				stage.Add(1)
+			}
+			results <- usages
+		}()
+	}
+
+	usages := make(map[string][]trailUsage)
+	for i := 0; i < workers; i++ {
+		for check, checkUsages := range <-results {
+			usages[check] = append(usages[check], checkUsages...)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
+
+// Return the CloudTrail log files under dir, skipping the directories of the trail layout that only hold files of
+// other accounts or regions or from before since. An empty account reads the files of every account.
+func archiveFiles(dir, account, region string, since time.Time) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if archiveDirSkipped(filepath.ToSlash(path), account, region, since) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".json.gz") || strings.HasSuffix(path, ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Return true if a directory of the trail layout only holds digest files, or log files of another account or region
+// or delivered before since
+func archiveDirSkipped(path, account, region string, since time.Time) bool {
+	if strings.HasSuffix(path, "/CloudTrail-Digest") {
+		return true
+	}
+	if match := archiveAccountDirPattern.FindStringSubmatch(path); match != nil {
+		return account != "" && match[1] != account
+	}
+	match := archiveDirPattern.FindStringSubmatch(path)
+	if match == nil {
+		return false
+	}
+	if region != "" && match[1] != region {
+		return true
+	}
+	if match[2] == "" {
+		return false
+	}
+
+	// The end of the year, month or day the directory holds
+	year, _ := strconv.Atoi(match[2])
+	end := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	if match[3] != "" {
+		month, _ := strconv.Atoi(match[3])
+		end = time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+		if match[4] != "" {
+			day, _ := strconv.Atoi(match[4])
+			end = time.Date(year, time.Month(month), day+1, 0, 0, 0, 0, time.UTC)
+		}
+	}
+	return !end.After(since)
+}
+
+// Decode a CloudTrail log file, gzipped or not, and add the usages of the events the filter selects
+func readArchiveFile(filter *trailFilter, path string, usages map[string][]trailUsage, stats *TrailStats) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	var content archiveFile
+	if err := json.NewDecoder(reader).Decode(&content); err != nil {
+		return err
+	}
+
+	for _, raw := range content.Records {
+		// Most events are of other APIs, so only the name and region are decoded before the whole event
+		var header struct {
+			EventName string `json:"eventName"`
+			AWSRegion string `json:"awsRegion"`
+		}
+		if err := json.Unmarshal(raw, &header); err != nil {
+			stats.addMalformed()
+			continue
+		}
+		if !filter.selects(header.EventName, header.AWSRegion) {
+			continue
+		}
+
+		record, err := parseTrailRecord(string(raw))
+		if err == nil {
+			err = filter.add(record, usages)
+		}
+		if err != nil {
+			log.Printf("Skipping CloudTrail event in %s: %v", path, err)
+			stats.addMalformed()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package checker
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+var archiveNow = time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+
+// Write a gzipped CloudTrail log file holding records under dir
+func writeArchiveFile(t *testing.T, dir, path string, records ...map[string]interface{}) {
+	t.Helper()
+	fullPath := filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatalf("Failed to create archive directory: %v", err)
+	}
+	file, err := os.Create(fullPath)
+	if err != nil {
+		t.Fatalf("Failed to create archive file: %v", err)
+	}
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	if err := json.NewEncoder(writer).Encode(map[string]interface{}{"Records": records}); err != nil {
+		t.Fatalf("Failed to write archive file: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to write archive file: %v", err)
+	}
+}
+
+// Return a CloudTrail record of a call in us-west-2 of account 123456789012
+func archiveRecord(eventName string, eventTime time.Time, requestParams map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"eventName":          eventName,
+		"eventTime":          eventTime.Format(time.RFC3339),
+		"awsRegion":          "us-west-2",
+		"recipientAccountId": "123456789012",
+		"userIdentity":       map[string]interface{}{"type": "IAMUser", "arn": "arn:aws:iam::123456789012:user/alice", "userName": "alice"},
+		"requestParameters":  requestParams,
+	}
+}
+
+// Write an archive in the layout of a trail bucket, with events of two regions, a year apart and some that cannot be used
+func writeTestArchive(t *testing.T) string {
+	dir := t.TempDir()
+	recent := archiveNow.AddDate(0, 0, -1)
+	failed := archiveRecord("GetLogEvents", recent, map[string]interface{}{"logGroupName": "log2"})
+	failed["errorCode"] = "AccessDenied"
+	east := archiveRecord("StartLiveTail", recent, map[string]interface{}{"logGroupIdentifiers": []interface{}{"log4"}})
+	east["awsRegion"] = "us-east-1"
+
+	writeArchiveFile(t, dir, "AWSLogs/123456789012/CloudTrail/us-west-2/2025/01/30/123456789012_CloudTrail_us-west-2_20250130T0000Z_a.json.gz",
+		archiveRecord("StartLiveTail", recent, map[string]interface{}{"logGroupIdentifiers": []interface{}{"arn:aws:logs:us-west-2:123456789012:log-group:log1:*"}}),
+		archiveRecord("CreateExportTask", recent, map[string]interface{}{"logGroupName": "log2"}),
+		archiveRecord("DescribeLogGroups", recent, nil),
+		failed,
+		map[string]interface{}{"eventName": "StartLiveTail", "awsRegion": "us-west-2"},
+	)
+	writeArchiveFile(t, dir, "AWSLogs/123456789012/CloudTrail/us-west-2/2024/06/01/123456789012_CloudTrail_us-west-2_20240601T0000Z_b.json.gz",
+		archiveRecord("StartLiveTail", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), map[string]interface{}{"logGroupIdentifiers": []interface{}{"log3"}}),
+	)
+	writeArchiveFile(t, dir, "AWSLogs/123456789012/CloudTrail/us-east-1/2025/01/30/123456789012_CloudTrail_us-east-1_20250130T0000Z_c.json.gz", east)
+	if err := os.WriteFile(filepath.Join(dir, "broken.json.gz"), []byte("not gzip"), 0644); err != nil {
+		t.Fatalf("Failed to write archive file: %v", err)
+	}
+	return dir
+}
+
+func TestReadTrailArchive(t *testing.T) {
+	dir := writeTestArchive(t)
+	env := &CheckEnv{
+		Region:     "us-west-2",
+		Now:        archiveNow,
+		Rules:      &Rules{Checks: map[string]CheckRule{CheckLiveTail: {LookbackDays: 365}}},
+		TrailStats: &TrailStats{},
+	}
+
+	usages, err := readTrailArchive(context.Background(), env, dir)
+	if err != nil {
+		t.Fatalf("readTrailArchive() unexpected error: %v", err)
+	}
+
+	names := func(check string) []string {
+		var names []string
+		for _, usage := range usages[check] {
+			names = append(names, usage.LogGroupName)
+		}
+		sort.Strings(names)
+		return names
+	}
+	// The year long lookback of live_tail reaches log3, the failed call and the other region are left out
+	if got := names(CheckLiveTail); !reflect.DeepEqual(got, []string{"log1", "log3"}) {
+		t.Errorf("live_tail log groups = %v, want [log1 log3]", got)
+	}
+	if got := names(CheckExportTask); !reflect.DeepEqual(got, []string{"log2"}) {
+		t.Errorf("export_task log groups = %v, want [log2]", got)
+	}
+	if got := names(CheckLogEventsRead); got != nil {
+		t.Errorf("log_events_read log groups = %v, want none", got)
+	}
+	// The event without a time and the file that is not gzipped
+	if malformed := env.TrailStats.Malformed(); malformed != 2 {
+		t.Errorf("Malformed() = %d, want 2", malformed)
+	}
+	// The files of the other accounts of an organization trail are not read
+	env.Account = "210987654321"
+	if usages, err := readTrailArchive(context.Background(), env, dir); err != nil || len(usages) != 0 {
+		t.Errorf("readTrailArchive() of another account = %v, %v, want no usages", usages, err)
+	}
+	if usage := usages[CheckExportTask][0]; usage.Account != "123456789012" || usage.Principal != "arn:aws:iam::123456789012:user/alice" || usage.UserName != "alice" {
+		t.Errorf("unexpected export_task usage: %+v", usage)
+	}
+}
+
+func TestTrailArchiveChecks(t *testing.T) {
+	env := &CheckEnv{
+		Region: "us-west-2",
+		Now:    archiveNow,
+		Rules:  DefaultRules(),
+		// LookupEvents must not be called when the archive is read
+		CloudTrail:   &mockCloudTrailClient{lookupEventsErr: errors.New("LookupEvents called")},
+		TrailArchive: writeTestArchive(t),
+	}
+
+	checks := checksNamed(CheckLiveTail, CheckExportTask, CheckLogEventsRead)
+	verdicts := verdictsFromNames("log1", "log2", "log3", "log4")
+	runChecksOnBatch(context.Background(), env, checks, verdicts)
+	// A log group of the same name in another account did not have the calls
+	otherAccount := &Verdict{LogGroupName: "log1", LogGroupArn: "arn:aws:logs:us-west-2:999999999999:log-group:log1"}
+	runChecksOnBatch(context.Background(), env, checks, []*Verdict{otherAccount})
+	verdicts = append(verdicts, otherAccount)
+
+	expected := []VerdictStatus{StatusIneligible, StatusIneligible, StatusEligible, StatusEligible, StatusEligible}
+	for i, v := range verdicts {
+		if status := v.Status(); status != expected[i] {
+			t.Errorf("%s (%s) status = %v, want %v: %v", v.LogGroupName, v.LogGroupArn, status, expected[i], v.Unknown)
+		}
+	}
+}
+
+func TestArchiveDirSkipped(t *testing.T) {
+	since := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "AWSLogs/123456789012", expected: false},
+		{path: "AWSLogs/210987654321", expected: true},
+		{path: "AWSLogs/o-abc123/123456789012", expected: false},
+		{path: "AWSLogs/o-abc123/210987654321", expected: true},
+		{path: "AWSLogs/123456789012/CloudTrail/us-west-2", expected: false},
+		{path: "AWSLogs/123456789012/CloudTrail/us-east-1", expected: true},
+		{path: "AWSLogs/o-abc123/123456789012/CloudTrail/us-east-1", expected: true},
+		{path: "AWSLogs/123456789012/CloudTrail/us-west-2/2024", expected: true},
+		{path: "AWSLogs/123456789012/CloudTrail/us-west-2/2025", expected: false},
+		{path: "AWSLogs/123456789012/CloudTrail/us-west-2/2024/12", expected: true},
+		{path: "AWSLogs/123456789012/CloudTrail/us-west-2/2025/01/14", expected: true},
+		{path: "AWSLogs/123456789012/CloudTrail/us-west-2/2025/01/15", expected: false},
+		{path: "AWSLogs/123456789012/CloudTrail-Digest", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if skipped := archiveDirSkipped(tt.path, "123456789012", "us-west-2", since); skipped != tt.expected {
+				t.Errorf("archiveDirSkipped(%q) = %v, want %v", tt.path, skipped, tt.expected)
+			}
+		})
+	}
+}
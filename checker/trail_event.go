@@ -0,0 +1,164 @@
+// This file contains the typed model of the CloudTrail events of CloudWatch Logs API calls and its parser.
+package checker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// trailRecord is the CloudTrailEvent of a LookupEvents result, with the fields the checks use
+type trailRecord struct {
+	EventName       string        `json:"eventName"`
+	EventTime       time.Time     `json:"eventTime"`
+	AWSRegion       string        `json:"awsRegion"`
+	AccountID       string        `json:"recipientAccountId"`
+	SourceIPAddress string        `json:"sourceIPAddress"`
+	UserAgent       string        `json:"userAgent"`
+	Identity        trailIdentity `json:"userIdentity"`
+	// Set when the call failed, e.g. "AccessDenied" or "ResourceNotFoundException"
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+	// The parameters differ per API and the paths holding log groups come from the TrailEvent, so they are kept as
+	// parsed JSON and read with logGroupNames
+	RequestParameters map[string]interface{} `json:"requestParameters"`
+}
+
+// trailIdentity is the userIdentity of a CloudTrail event
+type trailIdentity struct {
+	// e.g. "IAMUser", "AssumedRole" or "AWSService"
+	Type        string `json:"type"`
+	PrincipalID string `json:"principalId"`
+	ARN         string `json:"arn"`
+	AccountID   string `json:"accountId"`
+	// Set for IAM users
+	UserName string `json:"userName"`
+	// The AWS service that made the call on behalf of the principal, e.g. "logs.amazonaws.com"
+	InvokedBy string `json:"invokedBy"`
+	// Set for role sessions, the issuer is the role that was assumed
+	SessionContext struct {
+		SessionIssuer struct {
+			Type string `json:"type"`
+			ARN  string `json:"arn"`
+		} `json:"sessionIssuer"`
+	} `json:"sessionContext"`
+}
+
+// AppID is the application ID the command line tool sets on its AWS clients. CloudTrail records it in the user agent
+// of every call, which tells the calls of the checks apart from the usage of the log groups.
+const AppID = "log-ia-checker"
+
+// Log group names are 1 to 512 characters from this set
+var validLogGroupName = regexp.MustCompile(`^[\.\-_/#A-Za-z0-9]{1,512}$`)
+
+// errMalformedTrailEvent is returned for a CloudTrail event that cannot be used
+var errMalformedTrailEvent = errors.New("malformed CloudTrail event")
+
+// Parse the CloudTrailEvent JSON of a LookupEvents result. Events without a name or time are malformed.
+func parseTrailRecord(data string) (*trailRecord, error) {
+	var record trailRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMalformedTrailEvent, err)
+	}
+	if record.EventName == "" || record.EventTime.IsZero() {
+		return nil, fmt.Errorf("%w: missing eventName or eventTime", errMalformedTrailEvent)
+	}
+	return &record, nil
+}
+
+// Return true if the call of the event failed, in which case it did not use the log group
+func (r *trailRecord) failed() bool {
+	return r.ErrorCode != ""
+}
+
+// Return true if the checker made the call itself, e.g. the FilterLogEvents calls of the emf check
+func (r *trailRecord) ownCall() bool {
+	return strings.Contains(r.UserAgent, "app/"+AppID)
+}
+
+// Return who made the call: the ARN of the user or role session, or the AWS service that made it on their behalf
+func (r *trailRecord) principal() string {
+	switch {
+	case r.Identity.ARN != "":
+		return r.Identity.ARN
+	case r.Identity.InvokedBy != "":
+		return r.Identity.InvokedBy
+	}
+	return r.Identity.PrincipalID
+}
+
+// Return the usage of a Standard-only API the event records, for the given check
+func (r *trailRecord) usage(check string) Usage {
+	usage := Usage{
+		Check:         check,
+		EventName:     r.EventName,
+		EventTime:     r.EventTime,
+		Principal:     r.principal(),
+		PrincipalType: r.Identity.Type,
+		UserName:      r.Identity.UserName,
+		SourceIP:      r.SourceIPAddress,
+	}
+	if issuer := r.Identity.SessionContext.SessionIssuer; issuer.Type == "Role" {
+		usage.Role = issuer.ARN
+		usage.SessionName = sessionNameFromArn(r.Identity.ARN)
+	}
+	return usage
+}
+
+// Return the names of the log groups at the paths of the request parameters, whether they hold names or ARNs, with
+// or without the trailing ":*". A path that holds something other than a string or an array of strings, or an
+// identifier that is not a log group, makes the event malformed.
+func (r *trailRecord) logGroupNames(paths []string) ([]string, error) {
+	var names []string
+	for _, path := range paths {
+		identifiers, err := stringsAtPath(r.RequestParameters, path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: requestParameters.%s %v", errMalformedTrailEvent, path, err)
+		}
+		for _, identifier := range identifiers {
+			name := logGroupNameFromIdentifier(identifier)
+			if !validLogGroupName.MatchString(name) {
+				return nil, fmt.Errorf("%w: requestParameters.%s holds %q", errMalformedTrailEvent, path, identifier)
+			}
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Return the log groups the event names at the paths of a TrailEvent, with the usage it records
+func (r *trailRecord) trailUsages(event TrailEvent) ([]trailUsage, error) {
+	names, err := r.logGroupNames(event.LogGroupPaths)
+	if err != nil {
+		return nil, err
+	}
+	usages := make([]trailUsage, 0, len(names))
+	for _, name := range names {
+		usages = append(usages, trailUsage{LogGroupName: name, Account: r.AccountID, Usage: r.usage(event.Check)})
+	}
+	return usages, nil
+}
+
+// TrailStats counts the CloudTrail events the checks could not use. It is safe for concurrent use by the scanners
+// of every region, and a nil *TrailStats counts nothing.
+type TrailStats struct {
+	malformed atomic.Int64
+}
+
+// Malformed returns the number of CloudTrail events that could not be parsed
+func (s *TrailStats) Malformed() int {
+	if s == nil {
+		return 0
+	}
+	return int(s.malformed.Load())
+}
+
+func (s *TrailStats) addMalformed() {
+	if s != nil {
+		s.malformed.Add(1)
+	}
+}
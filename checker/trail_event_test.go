@@ -0,0 +1,253 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+func TestParseTrailRecord(t *testing.T) {
+	tests := []struct {
+		name              string
+		data              string
+		expectedPrincipal string
+		expectedFailed    bool
+		expectedErr       bool
+	}{
+		{
+			name:              "Role session",
+			data:              `{"eventName": "GetLogEvents", "eventTime": "2025-01-30T10:00:00Z", "userIdentity": {"type": "AssumedRole", "principalId": "AROA:alice", "arn": "arn:aws:sts::123456789012:assumed-role/ops/alice"}, "requestParameters": {"logGroupName": "log1"}}`,
+			expectedPrincipal: "arn:aws:sts::123456789012:assumed-role/ops/alice",
+		},
+		{
+			name:              "AWS service",
+			data:              `{"eventName": "CreateExportTask", "eventTime": "2025-01-30T10:00:00Z", "userIdentity": {"type": "AWSService", "invokedBy": "logs.amazonaws.com"}}`,
+			expectedPrincipal: "logs.amazonaws.com",
+		},
+		{
+			name:              "Failed call",
+			data:              `{"eventName": "GetLogEvents", "eventTime": "2025-01-30T10:00:00Z", "userIdentity": {"principalId": "AIDA"}, "errorCode": "AccessDenied", "requestParameters": null}`,
+			expectedPrincipal: "AIDA",
+			expectedFailed:    true,
+		},
+		{name: "Invalid JSON", data: `{"eventName": "GetLogEvents"`, expectedErr: true},
+		{name: "Missing eventTime", data: `{"eventName": "GetLogEvents"}`, expectedErr: true},
+		{name: "Invalid eventTime", data: `{"eventName": "GetLogEvents", "eventTime": "yesterday"}`, expectedErr: true},
+		{name: "Parameters are not an object", data: `{"eventName": "GetLogEvents", "eventTime": "2025-01-30T10:00:00Z", "requestParameters": "log1"}`, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := parseTrailRecord(tt.data)
+			if tt.expectedErr {
+				if !errors.Is(err, errMalformedTrailEvent) {
+					t.Errorf("parseTrailRecord() error = %v, want %v", err, errMalformedTrailEvent)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTrailRecord() unexpected error: %v", err)
+			}
+			if principal := record.principal(); principal != tt.expectedPrincipal {
+				t.Errorf("principal() = %q, want %q", principal, tt.expectedPrincipal)
+			}
+			if failed := record.failed(); failed != tt.expectedFailed {
+				t.Errorf("failed() = %v, want %v", failed, tt.expectedFailed)
+			}
+		})
+	}
+}
+
+func TestTrailRecordUsage(t *testing.T) {
+	record, err := parseTrailRecord(`{
+		"eventName": "StartLiveTail",
+		"eventTime": "2025-01-30T10:00:00Z",
+		"sourceIPAddress": "192.0.2.10",
+		"userIdentity": {
+			"type": "AssumedRole",
+			"principalId": "AROAEXAMPLE:alice",
+			"arn": "arn:aws:sts::123456789012:assumed-role/ops/alice",
+			"sessionContext": {"sessionIssuer": {"type": "Role", "arn": "arn:aws:iam::123456789012:role/ops"}}
+		}
+	}`)
+	if err != nil {
+		t.Fatalf("parseTrailRecord() unexpected error: %v", err)
+	}
+
+	expected := Usage{
+		Check:         CheckLiveTail,
+		EventName:     "StartLiveTail",
+		EventTime:     time.Date(2025, 1, 30, 10, 0, 0, 0, time.UTC),
+		Principal:     "arn:aws:sts::123456789012:assumed-role/ops/alice",
+		PrincipalType: "AssumedRole",
+		Role:          "arn:aws:iam::123456789012:role/ops",
+		SessionName:   "alice",
+		SourceIP:      "192.0.2.10",
+	}
+	if usage := record.usage(CheckLiveTail); !reflect.DeepEqual(usage, expected) {
+		t.Errorf("usage() = %+v, want %+v", usage, expected)
+	}
+}
+
+func TestTrailRecordLogGroupNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]interface{}
+		paths       []string
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:     "Name and ARN",
+			params:   map[string]interface{}{"logGroupName": "/aws/lambda/app", "logGroupIdentifier": "arn:aws:logs:us-west-2:123456789012:log-group:/ecs/web"},
+			paths:    []string{"logGroupName", "logGroupIdentifier"},
+			expected: []string{"/aws/lambda/app", "/ecs/web"},
+		},
+		{
+			name:     "Trailing :* on ARNs and names",
+			params:   map[string]interface{}{"logGroupIdentifiers": []interface{}{"arn:aws:logs:us-west-2:123456789012:log-group:log1:*", "log2:*"}},
+			paths:    []string{"logGroupIdentifiers"},
+			expected: []string{"log1", "log2"},
+		},
+		{
+			name:     "Missing parameters",
+			params:   nil,
+			paths:    []string{"logGroupName"},
+			expected: nil,
+		},
+		{
+			name:        "Number instead of a name",
+			params:      map[string]interface{}{"logGroupName": 42.0},
+			paths:       []string{"logGroupName"},
+			expectedErr: true,
+		},
+		{
+			name:        "Array with a number",
+			params:      map[string]interface{}{"logGroupIdentifiers": []interface{}{"log1", 42.0}},
+			paths:       []string{"logGroupIdentifiers"},
+			expectedErr: true,
+		},
+		{
+			name:        "ARN of another resource",
+			params:      map[string]interface{}{"logGroupIdentifier": "arn:aws:s3:::bucket"},
+			paths:       []string{"logGroupIdentifier"},
+			expectedErr: true,
+		},
+		{
+			name:        "Empty name",
+			params:      map[string]interface{}{"logGroupName": ""},
+			paths:       []string{"logGroupName"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &trailRecord{EventName: "GetLogEvents", RequestParameters: tt.params}
+			names, err := record.logGroupNames(tt.paths)
+			if tt.expectedErr {
+				if !errors.Is(err, errMalformedTrailEvent) {
+					t.Errorf("logGroupNames() error = %v, want %v", err, errMalformedTrailEvent)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("logGroupNames() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("logGroupNames() = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTrailLogGroupsSkipsFailedAndMalformedEvents(t *testing.T) {
+	mockClient := &mockCloudTrailClient{
+		lookupEventsOutput: &cloudtrail.LookupEventsOutput{
+			Events: []types.Event{
+				{CloudTrailEvent: aws.String(createMockCloudTrailEvent("GetLogEvents", map[string]interface{}{"logGroupName": "log1"}))},
+				{CloudTrailEvent: aws.String(`{"eventName": "GetLogEvents", "eventTime": "2025-01-30T10:00:00Z", "errorCode": "ResourceNotFoundException", "requestParameters": {"logGroupName": "log2"}}`)},
+				{CloudTrailEvent: aws.String(`{"eventName": "GetLogEvents", "eventTime": "2025-01-30T10:00:00Z", "requestParameters": {"logGroupName": ["log3", 3]}}`)},
+				{CloudTrailEvent: aws.String(`not json`)},
+				// The emf check sampling the log group
+				{CloudTrailEvent: aws.String(`{"eventName": "GetLogEvents", "eventTime": "2025-01-30T10:00:00Z", "userAgent": "aws-sdk-go-v2/1.36.0 ua/2.1 os/linux lang/go#1.24 md/GOOS#linux api/cloudwatchlogs#1.45.8 app/log-ia-checker", "requestParameters": {"logGroupName": "log4"}}`)},
+			},
+		},
+	}
+
+	stats := &TrailStats{}
+	event := TrailEvent{Check: CheckLogEventsRead, EventNames: []string{"GetLogEvents"}, LogGroupPaths: []string{"logGroupName"}}
+	usages, err := trailLogGroups(context.Background(), mockClient, event, time.Now(), defaultLookbackDays, stats)
+	if err != nil {
+		t.Fatalf("trailLogGroups() unexpected error: %v", err)
+	}
+
+	// The failed call did not read log2, the next two events cannot be used and log4 was read by the checker
+	if len(usages) != 1 || usages[0].LogGroupName != "log1" {
+		t.Errorf("trailLogGroups() = %v, want log1 only", usages)
+	}
+	if malformed := stats.Malformed(); malformed != 2 {
+		t.Errorf("Malformed() = %d, want 2", malformed)
+	}
+}
+
+func FuzzParseTrailRecord(f *testing.F) {
+	f.Add(createMockCloudTrailEvent("StartLiveTail", map[string]interface{}{"logGroupIdentifiers": []interface{}{"arn:aws:logs:us-west-2:123456789012:log-group:log1:*"}}))
+	f.Add(createMockCloudTrailEvent("CreateExportTask", map[string]interface{}{"logGroupName": "/aws/lambda/app"}))
+	f.Add(`{"eventName": "GetLogEvents", "eventTime": "2025-01-30T10:00:00Z", "errorCode": "AccessDenied", "userIdentity": {"arn": "arn:aws:iam::123456789012:user/bob"}}`)
+	f.Add(`{"eventName": "FilterLogEvents", "eventTime": "2025-01-30T10:00:00Z", "requestParameters": {"logGroupIdentifier": 7}}`)
+	f.Add(`{"eventName": "", "requestParameters": []}`)
+
+	paths := []string{"logGroupName", "logGroupIdentifier", "logGroupIdentifiers", "source.logGroupName"}
+	f.Fuzz(func(t *testing.T, data string) {
+		record, err := parseTrailRecord(data)
+		if err != nil {
+			if !errors.Is(err, errMalformedTrailEvent) {
+				t.Fatalf("parseTrailRecord() error %v is not %v", err, errMalformedTrailEvent)
+			}
+			return
+		}
+		if record.EventName == "" || record.EventTime.IsZero() {
+			t.Fatalf("parseTrailRecord() accepted an event without a name or time: %q", data)
+		}
+
+		names, err := record.logGroupNames(paths)
+		if err != nil {
+			if !errors.Is(err, errMalformedTrailEvent) {
+				t.Fatalf("logGroupNames() error %v is not %v", err, errMalformedTrailEvent)
+			}
+			return
+		}
+		// Every name must be a plain log group name, never an ARN or a name with the ":*" suffix
+		for _, name := range names {
+			if !validLogGroupName.MatchString(name) {
+				t.Fatalf("logGroupNames() returned %q from %q", name, data)
+			}
+		}
+	})
+}
+
+func FuzzLogGroupNameFromIdentifier(f *testing.F) {
+	f.Add("log1")
+	f.Add("/aws/lambda/my-function")
+	f.Add("/aws/containerinsights/cluster#1/performance")
+
+	f.Fuzz(func(t *testing.T, name string) {
+		if !validLogGroupName.MatchString(name) {
+			return
+		}
+		arn := "arn:aws:logs:us-west-2:123456789012:log-group:" + name
+		// Names, ARNs and either with the ":*" suffix all identify the same log group
+		for _, identifier := range []string{name, name + ":*", arn, arn + ":*"} {
+			if got := logGroupNameFromIdentifier(identifier); got != name {
+				t.Errorf("logGroupNameFromIdentifier(%q) = %q, want %q", identifier, got, name)
+			}
+		}
+	})
+}
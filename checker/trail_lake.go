@@ -0,0 +1,253 @@
+// This file reads the CloudTrail events of the checks from a CloudTrail Lake event data store. A single SQL query
+// fetches the events of every check, account and region at once, instead of the serial LookupEvents pages of every
+// event name in every account and region.
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+// CloudTrailLakeClient is an interface for the CloudTrail Lake query operations
+type CloudTrailLakeClient interface {
+	StartQuery(ctx context.Context, params *cloudtrail.StartQueryInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.StartQueryOutput, error)
+	GetQueryResults(ctx context.Context, params *cloudtrail.GetQueryResultsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.GetQueryResultsOutput, error)
+}
+
+// Time between two checks of a query that is still running
+var lakePollInterval = 2 * time.Second
+
+// Event data store IDs are the last part of their ARN, e.g. EXAMPLE-f852-4e8f-8bd1-bcf6cEXAMPLE
+var validEventDataStoreID = regexp.MustCompile(`^[A-Za-z0-9-]{1,128}$`)
+
+// Columns of the query, by alias. The results name their columns after the aliases.
+var lakeColumns = []struct {
+	expression string
+	alias      string
+}{
+	{"eventName", "eventName"},
+	{"eventTime", "eventTime"},
+	{"awsRegion", "awsRegion"},
+	{"recipientAccountId", "recipientAccountId"},
+	{"sourceIPAddress", "sourceIPAddress"},
+	{"userAgent", "userAgent"},
+	{"userIdentity.type", "identityType"},
+	{"userIdentity.principalId", "principalId"},
+	{"userIdentity.arn", "identityArn"},
+	{"userIdentity.userName", "userName"},
+	{"userIdentity.invokedBy", "invokedBy"},
+	{"userIdentity.sessionContext.sessionIssuer.type", "issuerType"},
+	{"userIdentity.sessionContext.sessionIssuer.arn", "issuerArn"},
+}
+
+// Layouts of the eventTime of query results
+var lakeTimeLayouts = []string{"2006-01-02 15:04:05.000", "2006-01-02 15:04:05", time.RFC3339}
+
+// TrailLake reads the events of the CloudTrail checks from a CloudTrail Lake event data store, in place of
+// LookupEvents. The event data store is queried once, for the events of every account and region, the first time a
+// scanner asks for them, and the scanners of every account and region share the results. They must all use the
+// same rules and time. It is safe for concurrent use.
+type TrailLake struct {
+	client         CloudTrailLakeClient
+	eventDataStore string
+
+	once sync.Once
+	// Usages per region and check
+	usages map[string]map[string][]trailUsage
+	err    error
+}
+
+// NewTrailLake returns a TrailLake that queries the event data store of the given ARN or ID with client, which must
+// be for the account and region of the event data store
+func NewTrailLake(client CloudTrailLakeClient, eventDataStore string) (*TrailLake, error) {
+	id := eventDataStore[strings.LastIndex(eventDataStore, "/")+1:]
+	if !validEventDataStoreID.MatchString(id) {
+		return nil, fmt.Errorf("invalid CloudTrail Lake event data store %q", eventDataStore)
+	}
+	return &TrailLake{client: client, eventDataStore: id}, nil
+}
+
+// Return the log groups named in the events of every enabled TrailEvent in the region of env, per check
+func (l *TrailLake) regionUsages(ctx context.Context, env *CheckEnv) (map[string][]trailUsage, error) {
+	l.once.Do(func() {
+		l.err = callRecovered(func() error {
+			var err error
+			l.usages, err = l.query(ctx, env)
+			return err
+		})
+	})
+	return l.usages[env.Region], l.err
+}
+
+// Run the query and return the usages it found per region and check. Failed calls, the calls of the checker and the
+// events outside the lookback window of their check are skipped, and events that cannot be used are logged and
+// counted in env.TrailStats.
+func (l *TrailLake) query(ctx context.Context, env *CheckEnv) (map[string]map[string][]trailUsage, error) {
+	filter := newTrailFilter(env.Rules, env.Now, "")
+	usages := make(map[string]map[string][]trailUsage)
+	if len(filter.events) == 0 {
+		return usages, nil
+	}
+	statement, params := lakeQuery(l.eventDataStore, filter)
+
+	log.Printf("Querying CloudTrail Lake event data store %s for %s events", l.eventDataStore, strings.Join(filter.eventNames(), ", "))
+	started, err := l.client.StartQuery(ctx, &cloudtrail.StartQueryInput{QueryStatement: aws.String(statement)})
+	if err != nil {
+		return nil, fmt.Errorf("starting CloudTrail Lake query: %w", err)
+	}
+
+	var rows int
+	input := &cloudtrail.GetQueryResultsInput{QueryId: started.QueryId}
+	for {
+		page, err := l.client.GetQueryResults(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving CloudTrail Lake query results: %w", err)
+		}
+
+		switch page.QueryStatus {
+		case types.QueryStatusQueued, types.QueryStatusRunning:
+			select {
+			case <-time.After(lakePollInterval):
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		case types.QueryStatusFinished:
+		default:
+			return nil, fmt.Errorf("CloudTrail Lake query %s %s: %s", aws.ToString(started.QueryId), page.QueryStatus, aws.ToString(page.ErrorMessage))
+		}
+
+		for _, row := range page.QueryResultRows {
+			rows++
+			record, err := lakeRecord(row, params)
+			if err == nil {
+				regionUsages, ok := usages[record.AWSRegion]
+				if !ok {
+					regionUsages = make(map[string][]trailUsage)
+					usages[record.AWSRegion] = regionUsages
+				}
+				err = filter.add(record, regionUsages)
+			}
+			if err != nil {
+				log.Printf("Skipping CloudTrail Lake event: %v", err)
+				env.TrailStats.addMalformed()
+			}
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+	log.Printf("Read %d events from CloudTrail Lake event data store %s", rows, l.eventDataStore)
+	return usages, nil
+}
+
+// Return the SQL query for the events of the filter in the event data store, with the request parameters it reads
+// by the alias of their column. The parameters are the first key of the log group paths of the TrailEvents.
+func lakeQuery(eventDataStore string, filter *trailFilter) (string, map[string]string) {
+	keys := make(map[string]bool)
+	for _, events := range filter.events {
+		for _, event := range events {
+			for _, path := range event.LogGroupPaths {
+				keys[strings.SplitN(path, ".", 2)[0]] = true
+			}
+		}
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	columns := make([]string, 0, len(lakeColumns)+len(sortedKeys))
+	for _, column := range lakeColumns {
+		columns = append(columns, fmt.Sprintf("%s AS %s", column.expression, column.alias))
+	}
+	params := make(map[string]string, len(sortedKeys))
+	for i, key := range sortedKeys {
+		alias := fmt.Sprintf("param%d", i)
+		params[alias] = key
+		columns = append(columns, fmt.Sprintf("element_at(requestParameters, %s) AS %s", sqlString(key), alias))
+	}
+
+	names := filter.eventNames()
+	quotedNames := make([]string, 0, len(names))
+	for _, name := range names {
+		quotedNames = append(quotedNames, sqlString(name))
+	}
+
+	const layout = "2006-01-02 15:04:05"
+	statement := fmt.Sprintf("SELECT %s FROM %s WHERE eventName IN (%s) AND eventTime >= %s AND eventTime <= %s AND errorCode IS NULL",
+		strings.Join(columns, ", "), eventDataStore, strings.Join(quotedNames, ", "),
+		sqlString(filter.since().UTC().Format(layout)), sqlString(filter.now.UTC().Format(layout)))
+	return statement, params
+}
+
+// Quote a string literal for a CloudTrail Lake query
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Return the event of a row of query results. Request parameters that are arrays or objects come back as JSON and
+// are decoded, so the log group paths of the TrailEvent read them the same way as the events of LookupEvents.
+func lakeRecord(row []map[string]string, params map[string]string) (*trailRecord, error) {
+	columns := make(map[string]string)
+	for _, cell := range row {
+		for name, value := range cell {
+			columns[name] = value
+		}
+	}
+
+	record := &trailRecord{
+		EventName:       columns["eventName"],
+		AWSRegion:       columns["awsRegion"],
+		AccountID:       columns["recipientAccountId"],
+		SourceIPAddress: columns["sourceIPAddress"],
+		UserAgent:       columns["userAgent"],
+	}
+	record.Identity.Type = columns["identityType"]
+	record.Identity.PrincipalID = columns["principalId"]
+	record.Identity.ARN = columns["identityArn"]
+	record.Identity.UserName = columns["userName"]
+	record.Identity.InvokedBy = columns["invokedBy"]
+	record.Identity.SessionContext.SessionIssuer.Type = columns["issuerType"]
+	record.Identity.SessionContext.SessionIssuer.ARN = columns["issuerArn"]
+	for _, layout := range lakeTimeLayouts {
+		if eventTime, err := time.Parse(layout, columns["eventTime"]); err == nil {
+			record.EventTime = eventTime
+			break
+		}
+	}
+	if record.EventName == "" || record.EventTime.IsZero() {
+		return nil, fmt.Errorf("%w: missing eventName or eventTime", errMalformedTrailEvent)
+	}
+
+	for alias, key := range params {
+		value := columns[alias]
+		if value == "" {
+			continue
+		}
+		if record.RequestParameters == nil {
+			record.RequestParameters = make(map[string]interface{})
+		}
+		var decoded interface{}
+		if (strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{")) && json.Unmarshal([]byte(value), &decoded) == nil {
+			record.RequestParameters[key] = decoded
+		} else {
+			record.RequestParameters[key] = value
+		}
+	}
+	return record, nil
+}
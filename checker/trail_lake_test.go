@@ -0,0 +1,264 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+// mockCloudTrailLakeClient returns its pages of query results in order
+type mockCloudTrailLakeClient struct {
+	mu         sync.Mutex
+	statements []string
+	startErr   error
+	pages      []*cloudtrail.GetQueryResultsOutput
+	tokens     []string
+}
+
+func (m *mockCloudTrailLakeClient) StartQuery(ctx context.Context, params *cloudtrail.StartQueryInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.StartQueryOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statements = append(m.statements, aws.ToString(params.QueryStatement))
+	if m.startErr != nil {
+		return nil, m.startErr
+	}
+	return &cloudtrail.StartQueryOutput{QueryId: aws.String("query-1")}, nil
+}
+
+func (m *mockCloudTrailLakeClient) GetQueryResults(ctx context.Context, params *cloudtrail.GetQueryResultsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.GetQueryResultsOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens = append(m.tokens, aws.ToString(params.NextToken))
+	page := m.pages[0]
+	m.pages = m.pages[1:]
+	return page, nil
+}
+
+// Return a row of query results for a call by alice in account 123456789012
+func lakeRow(eventName, eventTime, region string, params map[string]string) []map[string]string {
+	row := []map[string]string{
+		{"eventName": eventName},
+		{"eventTime": eventTime},
+		{"awsRegion": region},
+		{"recipientAccountId": "123456789012"},
+		{"identityType": "IAMUser"},
+		{"identityArn": "arn:aws:iam::123456789012:user/alice"},
+		{"userName": "alice"},
+	}
+	for alias, value := range params {
+		row = append(row, map[string]string{alias: value})
+	}
+	return row
+}
+
+func TestLakeQuery(t *testing.T) {
+	rules := &Rules{
+		Checks: map[string]CheckRule{CheckLiveTail: {LookbackDays: 365}, CheckExportTask: {Enabled: aws.Bool(false)}},
+		TrailEvents: []TrailEvent{
+			{Check: "o'brien", EventNames: []string{"Get'Events"}, LogGroupPaths: []string{"source.logGroupName"}},
+		},
+	}
+	statement, params := lakeQuery("eds-1", newTrailFilter(rules, archiveNow, ""))
+
+	for _, want := range []string{
+		"FROM eds-1 WHERE",
+		"eventName IN ('FilterLogEvents', 'Get''Events', 'GetLogEvents', 'StartLiveTail')",
+		"eventTime >= '2024-02-01 00:00:00' AND eventTime <= '2025-01-31 00:00:00'",
+		"errorCode IS NULL",
+		"element_at(requestParameters, 'logGroupIdentifier') AS param0",
+	} {
+		if !strings.Contains(statement, want) {
+			t.Errorf("lakeQuery() = %q, want it to contain %q", statement, want)
+		}
+	}
+	// The export task check is disabled
+	if strings.Contains(statement, "CreateExportTask") {
+		t.Errorf("lakeQuery() = %q, want no CreateExportTask", statement)
+	}
+	expected := map[string]string{"param0": "logGroupIdentifier", "param1": "logGroupIdentifiers", "param2": "logGroupName", "param3": "source"}
+	if !reflect.DeepEqual(params, expected) {
+		t.Errorf("lakeQuery() params = %v, want %v", params, expected)
+	}
+}
+
+func TestLakeRecord(t *testing.T) {
+	params := map[string]string{"param0": "logGroupIdentifiers", "param1": "source"}
+	tests := []struct {
+		name        string
+		row         []map[string]string
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:     "Array parameter",
+			row:      lakeRow("StartLiveTail", "2025-01-30 10:00:00.000", "us-west-2", map[string]string{"param0": `["arn:aws:logs:us-west-2:123456789012:log-group:log1:*","log2"]`}),
+			expected: []string{"log1", "log2"},
+		},
+		{
+			name:     "Nested parameter",
+			row:      lakeRow("StartLiveTail", "2025-01-30 10:00:00", "us-west-2", map[string]string{"param1": `{"logGroupName":"log3"}`}),
+			expected: []string{"log3"},
+		},
+		{
+			name:     "String parameter",
+			row:      lakeRow("StartLiveTail", "2025-01-30T10:00:00Z", "us-west-2", map[string]string{"param0": "log4"}),
+			expected: []string{"log4"},
+		},
+		{
+			name:        "Invalid eventTime",
+			row:         lakeRow("StartLiveTail", "yesterday", "us-west-2", nil),
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := lakeRecord(tt.row, params)
+			if tt.expectedErr {
+				if !errors.Is(err, errMalformedTrailEvent) {
+					t.Errorf("lakeRecord() error = %v, want %v", err, errMalformedTrailEvent)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("lakeRecord() unexpected error: %v", err)
+			}
+			if !record.EventTime.Equal(time.Date(2025, 1, 30, 10, 0, 0, 0, time.UTC)) || record.principal() != "arn:aws:iam::123456789012:user/alice" {
+				t.Errorf("lakeRecord() = %+v", record)
+			}
+			names, err := record.logGroupNames([]string{"logGroupIdentifiers", "source.logGroupName"})
+			if err != nil {
+				t.Fatalf("logGroupNames() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("logGroupNames() = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTrailLakeChecks(t *testing.T) {
+	defer func(interval time.Duration) { lakePollInterval = interval }(lakePollInterval)
+	lakePollInterval = time.Millisecond
+
+	recent := "2025-01-30 10:00:00.000"
+	client := &mockCloudTrailLakeClient{
+		pages: []*cloudtrail.GetQueryResultsOutput{
+			{QueryStatus: types.QueryStatusRunning},
+			{
+				QueryStatus: types.QueryStatusFinished,
+				NextToken:   aws.String("page-2"),
+				QueryResultRows: [][]map[string]string{
+					lakeRow("StartLiveTail", recent, "us-west-2", map[string]string{"param1": `["log1"]`}),
+					lakeRow("CreateExportTask", recent, "us-east-1", map[string]string{"param2": "log2"}),
+				},
+			},
+			{
+				QueryStatus: types.QueryStatusFinished,
+				QueryResultRows: [][]map[string]string{
+					lakeRow("GetLogEvents", recent, "us-west-2", map[string]string{"param2": "log3"}),
+					lakeRow("GetLogEvents", "", "us-west-2", nil),
+				},
+			},
+		},
+	}
+	lake, err := NewTrailLake(client, "arn:aws:cloudtrail:us-east-1:123456789012:eventdatastore/EXAMPLE-f852-4e8f")
+	if err != nil {
+		t.Fatalf("NewTrailLake() unexpected error: %v", err)
+	}
+
+	// The scanners of both regions share the results of a single query
+	stats := &TrailStats{}
+	checks := checksNamed(CheckLiveTail, CheckExportTask, CheckLogEventsRead)
+	statuses := make(map[string]VerdictStatus)
+	for _, region := range []string{"us-west-2", "us-east-1"} {
+		env := &CheckEnv{
+			Region:     region,
+			Now:        archiveNow,
+			Rules:      DefaultRules(),
+			CloudTrail: &mockCloudTrailClient{lookupEventsErr: errors.New("LookupEvents called")},
+			TrailStats: stats,
+			TrailLake:  lake,
+		}
+		verdicts := verdictsFromNames("log1", "log2", "log3")
+		runChecksOnBatch(context.Background(), env, checks, verdicts)
+		for _, v := range verdicts {
+			statuses[region+" "+v.LogGroupName] = v.Status()
+		}
+	}
+
+	expected := map[string]VerdictStatus{
+		"us-west-2 log1": StatusIneligible,
+		"us-west-2 log2": StatusEligible,
+		"us-west-2 log3": StatusIneligible,
+		"us-east-1 log1": StatusEligible,
+		"us-east-1 log2": StatusIneligible,
+		"us-east-1 log3": StatusEligible,
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("statuses = %v, want %v", statuses, expected)
+	}
+	if len(client.statements) != 1 || !strings.Contains(client.statements[0], "FROM EXAMPLE-f852-4e8f ") {
+		t.Errorf("StartQuery() statements = %q, want a single query of EXAMPLE-f852-4e8f", client.statements)
+	}
+	if !reflect.DeepEqual(client.tokens, []string{"", "", "page-2"}) {
+		t.Errorf("GetQueryResults() tokens = %q, want [\"\" \"\" \"page-2\"]", client.tokens)
+	}
+	if malformed := stats.Malformed(); malformed != 1 {
+		t.Errorf("Malformed() = %d, want 1", malformed)
+	}
+}
+
+func TestTrailLakeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *mockCloudTrailLakeClient
+	}{
+		{name: "StartQuery error", client: &mockCloudTrailLakeClient{startErr: errors.New("AccessDenied")}},
+		{
+			name: "Failed query",
+			client: &mockCloudTrailLakeClient{pages: []*cloudtrail.GetQueryResultsOutput{
+				{QueryStatus: types.QueryStatusFailed, ErrorMessage: aws.String("Query exceeded the scan limit")},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lake, err := NewTrailLake(tt.client, "EXAMPLE-f852-4e8f")
+			if err != nil {
+				t.Fatalf("NewTrailLake() unexpected error: %v", err)
+			}
+			env := &CheckEnv{Region: "us-west-2", Now: archiveNow, Rules: DefaultRules(), TrailLake: lake}
+			verdicts := verdictsFromNames("log1")
+			runChecksOnBatch(context.Background(), env, checksNamed(CheckLiveTail, CheckExportTask), verdicts)
+
+			// Every CloudTrail check is undetermined with the error of the single query
+			var unknown []string
+			for _, reason := range verdicts[0].Unknown {
+				unknown = append(unknown, reason.Check)
+			}
+			sort.Strings(unknown)
+			if !reflect.DeepEqual(unknown, []string{CheckExportTask, CheckLiveTail}) || len(tt.client.statements) != 1 {
+				t.Errorf("unknown checks = %v after %d queries, want both after 1", unknown, len(tt.client.statements))
+			}
+		})
+	}
+}
+
+func TestNewTrailLakeInvalidEventDataStore(t *testing.T) {
+	for _, eventDataStore := range []string{"", "eds; DROP TABLE", "arn:aws:cloudtrail:us-east-1:123456789012:eventdatastore/"} {
+		if _, err := NewTrailLake(&mockCloudTrailLakeClient{}, eventDataStore); err == nil {
+			t.Errorf("NewTrailLake(%q) expected an error", eventDataStore)
+		}
+	}
+}
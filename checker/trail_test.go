@@ -0,0 +1,352 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+// CloudTrailAPI defines the interface for CloudTrail client operations
+type CloudTrailAPI interface {
+	LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error)
+}
+
+// Mock CloudTrail client for testing
+type mockCloudTrailClient struct {
+	CloudTrailAPI
+	lookupEventsOutput *cloudtrail.LookupEventsOutput
+	lookupEventsErr    error
+}
+
+func (m *mockCloudTrailClient) LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error) {
+	return m.lookupEventsOutput, m.lookupEventsErr
+}
+
+func TestRemoveLiveTail(t *testing.T) {
+	// Create a CloudTrail event with LiveTail information
+	startLiveTailEvent := createMockCloudTrailEvent("StartLiveTail", map[string]interface{}{
+		"logGroupIdentifiers": []interface{}{
+			"arn:aws:logs:us-west-2:123456789012:log-group:log1",
+		},
+	})
+
+	tests := []struct {
+		name           string
+		logList        []string
+		mockResponse   *cloudtrail.LookupEventsOutput
+		mockError      error
+		expectedResult []string
+	}{
+		{
+			name:    "No LiveTail events found",
+			logList: []string{"log1", "log2", "log3"},
+			mockResponse: &cloudtrail.LookupEventsOutput{
+				Events: []types.Event{},
+			},
+			mockError:      nil,
+			expectedResult: []string{"log1", "log2", "log3"},
+		},
+		{
+			name:    "LiveTail events found for some logs",
+			logList: []string{"log1", "log2", "log3"},
+			mockResponse: &cloudtrail.LookupEventsOutput{
+				Events: []types.Event{
+					{
+						CloudTrailEvent: aws.String(startLiveTailEvent),
+					},
+				},
+			},
+			mockError:      nil,
+			expectedResult: []string{"log2", "log3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create mock client
+			mockClient := &mockCloudTrailClient{
+				lookupEventsOutput: tt.mockResponse,
+				lookupEventsErr:    tt.mockError,
+			}
+
+			verdicts := verdictsFromNames(tt.logList...)
+			logGroups, err := trailLogGroups(context.Background(), mockClient, liveTailEvent, time.Now(), defaultLookbackDays, nil)
+			if err != nil {
+				t.Fatalf("trailLogGroups() unexpected error: %v", err)
+			}
+			applyFindings(CheckLiveTail, verdicts, trailFindings(verdicts, logGroups, liveTailEvent, defaultLookbackDays))
+			result := EligibleNames(verdicts)
+			
+			if !reflect.DeepEqual(result, tt.expectedResult) {
+				t.Errorf("live_tail check = %v, want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestRemoveExport(t *testing.T) {
+	// Create a CloudTrail event with export information
+	exportEvent := createMockCloudTrailEvent("CreateExportTask", map[string]interface{}{
+		"logGroupName": "log1",
+	})
+
+	tests := []struct {
+		name           string
+		logList        []string
+		mockResponse   *cloudtrail.LookupEventsOutput
+		mockError      error
+		expectedResult []string
+	}{
+		{
+			name:    "No export events found",
+			logList: []string{"log1", "log2", "log3"},
+			mockResponse: &cloudtrail.LookupEventsOutput{
+				Events: []types.Event{},
+			},
+			mockError:      nil,
+			expectedResult: []string{"log1", "log2", "log3"},
+		},
+		{
+			name:    "Export events found for some logs",
+			logList: []string{"log1", "log2", "log3"},
+			mockResponse: &cloudtrail.LookupEventsOutput{
+				Events: []types.Event{
+					{
+						CloudTrailEvent: aws.String(exportEvent),
+					},
+				},
+			},
+			mockError:      nil,
+			expectedResult: []string{"log2", "log3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create mock client
+			mockClient := &mockCloudTrailClient{
+				lookupEventsOutput: tt.mockResponse,
+				lookupEventsErr:    tt.mockError,
+			}
+
+			verdicts := verdictsFromNames(tt.logList...)
+			logGroups, err := trailLogGroups(context.Background(), mockClient, exportTaskEvent, time.Now(), defaultLookbackDays, nil)
+			if err != nil {
+				t.Fatalf("trailLogGroups() unexpected error: %v", err)
+			}
+			applyFindings(CheckExportTask, verdicts, trailFindings(verdicts, logGroups, exportTaskEvent, defaultLookbackDays))
+			result := EligibleNames(verdicts)
+			
+			if !reflect.DeepEqual(result, tt.expectedResult) {
+				t.Errorf("export_task check = %v, want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestTrailChecksError(t *testing.T) {
+	env := &CheckEnv{
+		Now:        time.Now(),
+		Rules:      DefaultRules(),
+		CloudTrail: &mockCloudTrailClient{lookupEventsErr: errors.New("AccessDeniedException")},
+	}
+
+	verdicts := verdictsFromNames("log1", "log2")
+	runChecksOnBatch(context.Background(), env, checksNamed(CheckLiveTail, CheckExportTask), verdicts)
+
+	// Without CloudTrail there is no telling whether the log groups used LiveTail or export tasks
+	for _, v := range verdicts {
+		if status := v.Status(); status != StatusUnknown {
+			t.Errorf("%s status = %v, want %v", v.LogGroupName, status, StatusUnknown)
+		}
+		if len(v.Unknown) != 2 {
+			t.Errorf("%s unknown = %v, want both trail checks", v.LogGroupName, v.Unknown)
+		}
+	}
+}
+
+func TestTrailLogGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    TrailEvent
+		params   map[string]interface{}
+		expected []string
+	}{
+		{
+			name:     "Name",
+			event:    TrailEvent{EventNames: []string{"PutDataProtectionPolicy"}, LogGroupPaths: []string{"logGroupIdentifier"}},
+			params:   map[string]interface{}{"logGroupIdentifier": "log1"},
+			expected: []string{"log1"},
+		},
+		{
+			name:  "ARNs with and without the trailing :*",
+			event: TrailEvent{EventNames: []string{"StartQuery"}, LogGroupPaths: []string{"logGroupIdentifiers"}},
+			params: map[string]interface{}{"logGroupIdentifiers": []interface{}{
+				"arn:aws:logs:us-west-2:123456789012:log-group:log1:*",
+				"arn:aws:logs:us-west-2:123456789012:log-group:log2",
+			}},
+			expected: []string{"log1", "log2"},
+		},
+		{
+			name:     "Nested path",
+			event:    TrailEvent{EventNames: []string{"CreateDelivery"}, LogGroupPaths: []string{"source.logGroupName"}},
+			params:   map[string]interface{}{"source": map[string]interface{}{"logGroupName": "log1"}},
+			expected: []string{"log1"},
+		},
+		{
+			name:     "Several paths",
+			event:    TrailEvent{EventNames: []string{"StartQuery"}, LogGroupPaths: []string{"logGroupName", "logGroupNames"}},
+			params:   map[string]interface{}{"logGroupName": "log1", "logGroupNames": []interface{}{"log2"}},
+			expected: []string{"log1", "log2"},
+		},
+		{
+			name:     "Missing path",
+			event:    TrailEvent{EventNames: []string{"CreateDelivery"}, LogGroupPaths: []string{"source.logGroupName"}},
+			params:   map[string]interface{}{"destination": "log1"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockCloudTrailClient{
+				lookupEventsOutput: &cloudtrail.LookupEventsOutput{
+					Events: []types.Event{
+						{CloudTrailEvent: aws.String(createMockCloudTrailEvent(tt.event.EventNames[0], tt.params))},
+						// Events of other APIs are ignored
+						{CloudTrailEvent: aws.String(createMockCloudTrailEvent("OtherEvent", tt.params))},
+					},
+				},
+			}
+
+			usages, err := trailLogGroups(context.Background(), mockClient, tt.event, time.Now(), defaultLookbackDays, nil)
+			if err != nil {
+				t.Fatalf("trailLogGroups() unexpected error: %v", err)
+			}
+			var logGroups []string
+			for _, usage := range usages {
+				logGroups = append(logGroups, usage.LogGroupName)
+			}
+			if !reflect.DeepEqual(logGroups, tt.expected) {
+				t.Errorf("trailLogGroups() = %v, want %v", logGroups, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInformationalTrailEvent(t *testing.T) {
+	event := TrailEvent{Check: "insights_query", EventNames: []string{"StartQuery"}, LogGroupPaths: []string{"logGroupName"}, Informational: true}
+	env := &CheckEnv{
+		Now:   time.Now(),
+		Rules: DefaultRules(),
+		CloudTrail: &mockCloudTrailClient{
+			lookupEventsOutput: &cloudtrail.LookupEventsOutput{
+				Events: []types.Event{
+					{CloudTrailEvent: aws.String(createMockCloudTrailEvent("StartQuery", map[string]interface{}{"logGroupName": "log1"}))},
+				},
+			},
+		},
+	}
+
+	verdicts := verdictsFromNames("log1", "log2")
+	runChecksOnBatch(context.Background(), env, []Check{trailEventCheck(event)}, verdicts)
+
+	// An informational event is reported on the log group but does not exclude it
+	if status := verdicts[0].Status(); status != StatusEligible {
+		t.Errorf("log1 status = %v, want %v", status, StatusEligible)
+	}
+	if result := verdicts[0].checkResult("insights_query"); result != "pass: 1 StartQuery events in the last 30 days" {
+		t.Errorf("log1 checkResult() = %q", result)
+	}
+	if result := verdicts[1].checkResult("insights_query"); result != "pass" {
+		t.Errorf("log2 checkResult() = %q, want pass", result)
+	}
+}
+
+func TestLogEventsReadFindings(t *testing.T) {
+	usages := []trailUsage{
+		{LogGroupName: "log1", Usage: Usage{EventName: "GetLogEvents", Principal: "arn:aws:sts::123456789012:assumed-role/ops/alice"}},
+		{LogGroupName: "log1", Usage: Usage{EventName: "GetLogEvents", Principal: "arn:aws:sts::123456789012:assumed-role/ops/alice"}},
+		{LogGroupName: "log1", Usage: Usage{EventName: "FilterLogEvents", Principal: "arn:aws:iam::123456789012:user/tail-script"}},
+		{LogGroupName: "log2", Usage: Usage{EventName: "FilterLogEvents"}},
+		{LogGroupName: "log3", Usage: Usage{EventName: "GetLogEvents", Principal: "a"}},
+		{LogGroupName: "log3", Usage: Usage{EventName: "GetLogEvents", Principal: "b"}},
+		{LogGroupName: "log3", Usage: Usage{EventName: "GetLogEvents", Principal: "c"}},
+		{LogGroupName: "log3", Usage: Usage{EventName: "GetLogEvents", Principal: "d"}},
+	}
+	verdicts := verdictsFromNames("log1", "log2", "log3", "log4")
+
+	findings := trailFindings(verdicts, usages, logEventsReadEvent, defaultLookbackDays)
+
+	expected := map[string]string{
+		"log1": "2 GetLogEvents and 1 FilterLogEvents events in the last 30 days by arn:aws:iam::123456789012:user/tail-script, arn:aws:sts::123456789012:assumed-role/ops/alice",
+		"log2": "1 FilterLogEvents events in the last 30 days",
+		"log3": "4 GetLogEvents events in the last 30 days by a, b, c and 1 more",
+	}
+	details := make(map[string]string)
+	for _, finding := range findings {
+		details[finding.LogGroupName] = finding.Detail
+	}
+	if !reflect.DeepEqual(details, expected) {
+		t.Errorf("trailFindings() details = %v, want %v", details, expected)
+	}
+	if len(findings) == 3 && len(findings[0].Usages) != 3 {
+		t.Errorf("log1 finding has %d usages, want 3", len(findings[0].Usages))
+	}
+}
+
+func TestLogEventsReadInformational(t *testing.T) {
+	flag := true
+	env := &CheckEnv{
+		Now:   time.Now(),
+		Rules: &Rules{Checks: map[string]CheckRule{CheckLogEventsRead: {Informational: &flag}}},
+		CloudTrail: &mockCloudTrailClient{
+			lookupEventsOutput: &cloudtrail.LookupEventsOutput{
+				Events: []types.Event{
+					{CloudTrailEvent: aws.String(createMockCloudTrailEvent("GetLogEvents", map[string]interface{}{"logGroupName": "log1"}))},
+				},
+			},
+		},
+	}
+
+	verdicts := verdictsFromNames("log1")
+	runChecksOnBatch(context.Background(), env, checksNamed(CheckLogEventsRead), verdicts)
+
+	// The rules can flag the log groups read directly instead of excluding them
+	if status := verdicts[0].Status(); status != StatusEligible || len(verdicts[0].Notes) != 1 {
+		t.Errorf("log1 status = %v with notes %v, want %v with a note", status, verdicts[0].Notes, StatusEligible)
+	}
+}
+
+// Helper function to create mock CloudTrail event JSON
+func createMockCloudTrailEvent(eventName string, requestParams map[string]interface{}) string {
+	event := map[string]interface{}{
+		"eventName":         eventName,
+		"eventTime":         time.Now().Format(time.RFC3339),
+		"requestParameters": requestParams,
+	}
+	
+	jsonBytes, _ := json.Marshal(event)
+	return string(jsonBytes)
+}
+
+// Helper function to create verdicts that are still in consideration from log group names
+func verdictsFromNames(names ...string) []*Verdict {
+	var verdicts []*Verdict
+	for _, name := range names {
+		verdicts = append(verdicts, &Verdict{
+			Account:      "123456789012",
+			Region:       "us-west-2",
+			LogGroupName: name,
+			LogGroupArn:  "arn:aws:logs:us-west-2:123456789012:log-group:" + name,
+		})
+	}
+	return verdicts
+}
@@ -0,0 +1,130 @@
+// This file contains the attribution of Standard-only API calls to the principals that made them, so the owners of
+// an excluded log group can be asked whether they still need the feature.
+package checker
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Number of the most recent usages kept per log group
+const usagesPerLogGroup = 5
+
+// Usage is a call to a Standard-only API on a log group, found in CloudTrail
+type Usage struct {
+	Check     string    `json:"check"`
+	EventName string    `json:"eventName"`
+	EventTime time.Time `json:"eventTime"`
+	// ARN of the user or role session, or the AWS service that made the call
+	Principal     string `json:"principal"`
+	PrincipalType string `json:"principalType,omitempty"`
+	// Role a role session was assumed from, and the name of the session
+	Role        string `json:"role,omitempty"`
+	SessionName string `json:"sessionName,omitempty"`
+	// Name of the IAM user that made the call
+	UserName string `json:"userName,omitempty"`
+	SourceIP string `json:"sourceIPAddress,omitempty"`
+}
+
+// PrincipalUsage counts the calls of a single principal on a log group
+type PrincipalUsage struct {
+	Principal string    `json:"principal"`
+	Events    int       `json:"events"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// Record the usages a check found on the log group: the most recent ones and the calls of every principal
+func (v *Verdict) recordUsages(usages []Usage) {
+	v.Usages = append(v.Usages, usages...)
+	sort.SliceStable(v.Usages, func(i, j int) bool {
+		return v.Usages[i].EventTime.After(v.Usages[j].EventTime)
+	})
+	if len(v.Usages) > usagesPerLogGroup {
+		v.Usages = v.Usages[:usagesPerLogGroup:usagesPerLogGroup]
+	}
+
+	index := make(map[string]int, len(v.Principals))
+	for i, principal := range v.Principals {
+		index[principal.Principal] = i
+	}
+	for _, usage := range usages {
+		if usage.Principal == "" {
+			continue
+		}
+		i, ok := index[usage.Principal]
+		if !ok {
+			i = len(v.Principals)
+			index[usage.Principal] = i
+			v.Principals = append(v.Principals, PrincipalUsage{Principal: usage.Principal})
+		}
+		v.Principals[i].Events++
+		if usage.EventTime.After(v.Principals[i].LastUsed) {
+			v.Principals[i].LastUsed = usage.EventTime
+		}
+	}
+	sort.Slice(v.Principals, func(i, j int) bool {
+		return v.Principals[i].Principal < v.Principals[j].Principal
+	})
+}
+
+// principalRecord is the rollup of the log groups a principal used Standard-only APIs on
+type principalRecord struct {
+	Principal string              `json:"principal"`
+	Events    int                 `json:"events"`
+	LastUsed  time.Time           `json:"lastUsed"`
+	LogGroups []principalLogGroup `json:"logGroups"`
+}
+
+type principalLogGroup struct {
+	Account      string    `json:"account"`
+	Region       string    `json:"region"`
+	LogGroupName string    `json:"logGroupName"`
+	Events       int       `json:"events"`
+	LastUsed     time.Time `json:"lastUsed"`
+}
+
+// Roll the principals of every log group up per principal, the most active first
+func principalRecords(verdicts []*Verdict) []principalRecord {
+	byPrincipal := make(map[string]*principalRecord)
+	for _, v := range verdicts {
+		for _, usage := range v.Principals {
+			record, ok := byPrincipal[usage.Principal]
+			if !ok {
+				record = &principalRecord{Principal: usage.Principal}
+				byPrincipal[usage.Principal] = record
+			}
+			record.Events += usage.Events
+			if usage.LastUsed.After(record.LastUsed) {
+				record.LastUsed = usage.LastUsed
+			}
+			record.LogGroups = append(record.LogGroups, principalLogGroup{
+				Account:      v.Account,
+				Region:       v.Region,
+				LogGroupName: v.LogGroupName,
+				Events:       usage.Events,
+				LastUsed:     usage.LastUsed,
+			})
+		}
+	}
+
+	records := make([]principalRecord, 0, len(byPrincipal))
+	for _, record := range byPrincipal {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Events != records[j].Events {
+			return records[i].Events > records[j].Events
+		}
+		return records[i].Principal < records[j].Principal
+	})
+	return records
+}
+
+// Return the name of the session of an assumed role ARN, e.g. alice for arn:aws:sts::123456789012:assumed-role/ops/alice
+func sessionNameFromArn(arn string) string {
+	if !strings.Contains(arn, ":assumed-role/") {
+		return ""
+	}
+	return arn[strings.LastIndex(arn, "/")+1:]
+}
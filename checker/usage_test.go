@@ -0,0 +1,95 @@
+package checker
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecordUsages(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var liveTail, export []Usage
+	for day := 0; day < 4; day++ {
+		liveTail = append(liveTail, Usage{Check: CheckLiveTail, EventName: "StartLiveTail", EventTime: start.AddDate(0, 0, day), Principal: "alice"})
+	}
+	for day := 0; day < 3; day++ {
+		export = append(export, Usage{Check: CheckExportTask, EventName: "CreateExportTask", EventTime: start.AddDate(0, 0, 10+day), Principal: "bob"})
+	}
+	export = append(export, Usage{Check: CheckExportTask, EventName: "CreateExportTask", EventTime: start.AddDate(0, 0, 20)})
+
+	verdict := verdictsFromNames("log1")[0]
+	verdict.recordUsages(liveTail)
+	verdict.recordUsages(export)
+
+	// Only the most recent usages of both checks are kept, newest first
+	if len(verdict.Usages) != usagesPerLogGroup {
+		t.Fatalf("got %d usages, want %d", len(verdict.Usages), usagesPerLogGroup)
+	}
+	if first, last := verdict.Usages[0].EventTime, verdict.Usages[usagesPerLogGroup-1].EventTime; !first.Equal(start.AddDate(0, 0, 20)) || !last.Equal(start.AddDate(0, 0, 3)) {
+		t.Errorf("usages span %v to %v, want the 5 most recent", first, last)
+	}
+
+	// Every principal is counted, even those whose calls are no longer among the most recent
+	expected := []PrincipalUsage{
+		{Principal: "alice", Events: 4, LastUsed: start.AddDate(0, 0, 3)},
+		{Principal: "bob", Events: 3, LastUsed: start.AddDate(0, 0, 12)},
+	}
+	if !reflect.DeepEqual(verdict.Principals, expected) {
+		t.Errorf("Principals = %+v, want %+v", verdict.Principals, expected)
+	}
+}
+
+func TestPrincipalRecords(t *testing.T) {
+	lastUsed := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	verdicts := verdictsFromNames("log1", "log2", "log3")
+	verdicts[0].Principals = []PrincipalUsage{{Principal: "alice", Events: 1, LastUsed: lastUsed}, {Principal: "bob", Events: 5, LastUsed: lastUsed}}
+	verdicts[1].Principals = []PrincipalUsage{{Principal: "alice", Events: 2, LastUsed: lastUsed.AddDate(0, 0, 1)}}
+
+	records := principalRecords(verdicts)
+
+	if len(records) != 2 {
+		t.Fatalf("got %d principals, want 2", len(records))
+	}
+	// The most active principal comes first
+	if records[0].Principal != "bob" || records[0].Events != 5 || len(records[0].LogGroups) != 1 {
+		t.Errorf("unexpected first principal: %+v", records[0])
+	}
+	if records[1].Principal != "alice" || records[1].Events != 3 || !records[1].LastUsed.Equal(lastUsed.AddDate(0, 0, 1)) {
+		t.Errorf("unexpected second principal: %+v", records[1])
+	}
+	if names := []string{records[1].LogGroups[0].LogGroupName, records[1].LogGroups[1].LogGroupName}; !reflect.DeepEqual(names, []string{"log1", "log2"}) {
+		t.Errorf("alice log groups = %v, want [log1 log2]", names)
+	}
+}
+
+func TestWriteJSONReportPrincipals(t *testing.T) {
+	tempFile := "test_output_principals.json"
+	defer os.Remove(tempFile)
+
+	report := testReport()
+	report.Verdicts[1].recordUsages([]Usage{{Check: CheckLiveTail, EventName: "StartLiveTail", EventTime: time.Date(2025, 1, 30, 10, 0, 0, 0, time.UTC), Principal: "arn:aws:sts::123456789012:assumed-role/ops/alice", SourceIP: "192.0.2.10"}})
+
+	if err := WriteReport(tempFile, FormatJSON, report); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	content, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	var decoded struct {
+		LogGroups  []logGroupRecord  `json:"logGroups"`
+		Principals []principalRecord `json:"principals"`
+	}
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if usages := decoded.LogGroups[1].Usages; len(usages) != 1 || usages[0].SourceIP != "192.0.2.10" {
+		t.Errorf("log2 usages = %+v, want the StartLiveTail call", usages)
+	}
+	if len(decoded.Principals) != 1 || decoded.Principals[0].LogGroups[0].LogGroupName != "log2" {
+		t.Errorf("principals = %+v, want alice on log2", decoded.Principals)
+	}
+}
@@ -0,0 +1,185 @@
+// This file contains utility functions
+package checker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Change log group arns in list to just log group. This is necessary because some checks return arn and some return names.
+// We will have the final output just be names because deriving the arn from name would require a second call to describe log group.
+// Potentially we could do it by extracting the region and account id from other variables.
+func parseLogGroupArns(logArns []string) []string {
+	var logGroupNames []string
+
+	for _, arn := range logArns {
+		// Split by colon and get the last part after "log-group:"
+		parts := strings.Split(arn, ":log-group:")
+		if len(parts) > 1 {
+			logGroupNames = append(logGroupNames, parts[1])
+		}
+	}
+
+	return logGroupNames
+}
+
+func parseLogGroupArn(logGroupArn *string) string {
+	// Assuming the log group ARN looks like: arn:aws:logs:region:account-id:log-group:log-group-name
+	arnParts := strings.Split(aws.ToString(logGroupArn), ":log-group:")
+	if len(arnParts) > 1 {
+		return arnParts[1]
+	}
+	return ""
+}
+
+// Return the log group name for an identifier that may be a name or an ARN, with or without the trailing ":*"
+func logGroupNameFromIdentifier(identifier string) string {
+	if strings.Contains(identifier, ":log-group:") {
+		identifier = parseLogGroupArn(&identifier)
+	}
+	return strings.TrimSuffix(identifier, ":*")
+}
+
+// Return the account ID from an ARN such as arn:aws:logs:region:account-id:log-group:log-group-name
+func accountFromArn(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) > 4 && parts[0] == "arn" {
+		return parts[4]
+	}
+	return ""
+}
+
+func writeToFile(fileName string, lines []string) error {
+	// Open the file for writing (create if it doesn't exist)
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Create a buffered writer
+	writer := bufio.NewWriter(file)
+
+	// Write each line to the file
+	for _, line := range lines {
+		_, err := writer.WriteString(line + "\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Flush the buffered writer to ensure all data is written
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Write one tab separated line per log group with its status and the reasons that fired. As in the text output, the
+// lines start with the account and region of the log group when the verdicts span several of them.
+func WriteVerdictsFile(fileName string, verdicts []*Verdict) error {
+	accounts := make(map[string]bool)
+	regions := make(map[string]bool)
+	for _, v := range verdicts {
+		accounts[v.Account] = true
+		regions[v.Region] = true
+	}
+	writer, err := NewVerdictsWriter(fileName, len(accounts) > 1, len(regions) > 1)
+	if err != nil {
+		return err
+	}
+	for _, v := range verdicts {
+		if err := writer.Write(v); err != nil {
+			writer.Close()
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// VerdictsWriter writes the lines of WriteVerdictsFile one verdict at a time, so they can be written while the
+// scan is still running. It is safe for concurrent use by the scanners of several regions.
+type VerdictsWriter struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	// Prefix the lines with the account and region
+	multiAccount bool
+	multiRegion  bool
+}
+
+// NewVerdictsWriter creates the file and returns a VerdictsWriter writing to it. The lines are prefixed with the
+// account when multiAccount is set and with the region when multiRegion is set.
+func NewVerdictsWriter(fileName string, multiAccount, multiRegion bool) (*VerdictsWriter, error) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return &VerdictsWriter{file: file, writer: bufio.NewWriter(file), multiAccount: multiAccount, multiRegion: multiRegion}, nil
+}
+
+// Write the line of a verdict
+func (w *VerdictsWriter) Write(v *Verdict) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := fmt.Fprintf(w.writer, "%s\t%s\t%s\n", textLine(v, w.multiAccount, w.multiRegion), v.Status(), v.reasonSummary())
+	return err
+}
+
+// Close flushes the lines that are still buffered and closes the file
+func (w *VerdictsWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.writer.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// Format a dollar amount with two decimals and thousands separators, e.g. 12345.6 -> 12,345.60
+func FormatMoney(value float64) string {
+	formatted := fmt.Sprintf("%.2f", value)
+	whole, cents := formatted[:len(formatted)-3], formatted[len(formatted)-3:]
+
+	sign := ""
+	if strings.HasPrefix(whole, "-") {
+		sign, whole = "-", whole[1:]
+	}
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return sign + whole + cents
+}
+
+// Simple progress bar function
+func progressBar(current, total int) string {
+	// Calculate the percentage
+	percentage := float64(current) / float64(total) * 100
+
+	// The length of the progress bar
+	barLength := 50
+	progress := int(float64(barLength) * percentage / 100.0)
+
+	// Format the progress bar
+	return fmt.Sprintf("[%s%s] %.2f%% (%d/%d)",
+		string(replicate('=', progress)),
+		string(replicate(' ', barLength-progress)),
+		percentage, current, total)
+}
+
+// Helper function to replicate characters in progress bar
+func replicate(char rune, count int) []rune {
+	result := make([]rune, count)
+	for i := 0; i < count; i++ {
+		result[i] = char
+	}
+	return result
+}
@@ -0,0 +1,333 @@
+package checker
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLogGroupArns(t *testing.T) {
+	tests := []struct {
+		name     string
+		logArns  []string
+		expected []string
+	}{
+		{
+			name: "Valid ARNs",
+			logArns: []string{
+				"arn:aws:logs:us-west-2:123456789012:log-group:my-log-group",
+				"arn:aws:logs:us-east-1:123456789012:log-group:another-log-group",
+			},
+			expected: []string{
+				"my-log-group",
+				"another-log-group",
+			},
+		},
+		{
+			name:     "Empty list",
+			logArns:  []string{},
+			expected: nil,
+		},
+		{
+			name: "Invalid ARNs",
+			logArns: []string{
+				"invalid-arn",
+				"arn:aws:logs:us-west-2:123456789012:not-a-log-group",
+			},
+			expected: nil,
+		},
+		{
+			name: "Mixed valid and invalid ARNs",
+			logArns: []string{
+				"arn:aws:logs:us-west-2:123456789012:log-group:valid-group",
+				"invalid-arn",
+			},
+			expected: []string{
+				"valid-group",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseLogGroupArns(tt.logArns)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseLogGroupArns() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseLogGroupArn(t *testing.T) {
+	tests := []struct {
+		name     string
+		arn      string
+		expected string
+	}{
+		{
+			name:     "Valid ARN",
+			arn:      "arn:aws:logs:us-west-2:123456789012:log-group:my-log-group",
+			expected: "my-log-group",
+		},
+		{
+			name:     "Invalid ARN",
+			arn:      "invalid-arn",
+			expected: "",
+		},
+		{
+			name:     "Empty ARN",
+			arn:      "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseLogGroupArn(&tt.arn)
+			if result != tt.expected {
+				t.Errorf("parseLogGroupArn() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteToFile(t *testing.T) {
+	// Create a temporary file for testing
+	tempFile := "test_output.txt"
+	defer os.Remove(tempFile) // Clean up after test
+
+	tests := []struct {
+		name     string
+		lines    []string
+		expected []string
+	}{
+		{
+			name:     "Write multiple lines",
+			lines:    []string{"line1", "line2", "line3"},
+			expected: []string{"line1", "line2", "line3"},
+		},
+		{
+			name:     "Write empty list",
+			lines:    []string{},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Write to file
+			err := writeToFile(tempFile, tt.lines)
+			if err != nil {
+				t.Fatalf("writeToFile() error = %v", err)
+			}
+
+			// Read the file back to verify contents
+			content, err := os.ReadFile(tempFile)
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+
+			// Check if file content matches expected
+			expected := ""
+			for _, line := range tt.expected {
+				expected += line + "\n"
+			}
+
+			if string(content) != expected {
+				t.Errorf("File content = %v, want %v", string(content), expected)
+			}
+		})
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	tests := []struct {
+		name     string
+		char     rune
+		count    int
+		expected []rune
+	}{
+		{
+			name:     "Replicate equals sign",
+			char:     '=',
+			count:    5,
+			expected: []rune{'=', '=', '=', '=', '='},
+		},
+		{
+			name:     "Replicate space",
+			char:     ' ',
+			count:    3,
+			expected: []rune{' ', ' ', ' '},
+		},
+		{
+			name:     "Zero count",
+			char:     'x',
+			count:    0,
+			expected: []rune{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := replicate(tt.char, tt.count)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("replicate() = %v, want %v", string(result), string(tt.expected))
+			}
+		})
+	}
+}
+func TestLogGroupNameFromIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		expected   string
+	}{
+		{"Plain name", "my-log-group", "my-log-group"},
+		{"ARN", "arn:aws:logs:us-west-2:123456789012:log-group:my-log-group", "my-log-group"},
+		{"ARN with wildcard suffix", "arn:aws:logs:us-west-2:123456789012:log-group:my-log-group:*", "my-log-group"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := logGroupNameFromIdentifier(tt.identifier); result != tt.expected {
+				t.Errorf("logGroupNameFromIdentifier() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteVerdictsFile(t *testing.T) {
+	tempFile := "test_output.txt"
+	defer os.Remove(tempFile)
+
+	verdicts := verdictsFromNames("log1", "log2")
+	verdicts[1].exclude(CheckMetricFilter, "2 metric filters")
+	verdicts[1].exclude(CheckLiveTail, "1 StartLiveTail events in the last 30 days")
+
+	if err := WriteVerdictsFile(tempFile, verdicts); err != nil {
+		t.Fatalf("WriteVerdictsFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	expected := "log1\teligible\t\nlog2\tineligible\tmetric_filter: 2 metric filters; live_tail: 1 StartLiveTail events in the last 30 days\n"
+	if string(content) != expected {
+		t.Errorf("File content = %q, want %q", string(content), expected)
+	}
+}
+
+func TestWriteVerdictsFileMultiRegion(t *testing.T) {
+	tempFile := "test_output.txt"
+	defer os.Remove(tempFile)
+
+	verdicts := verdictsFromNames("log1", "log1")
+	verdicts[0].Account, verdicts[0].Region = "123456789012", "us-east-1"
+	verdicts[1].Account, verdicts[1].Region = "123456789012", "us-west-2"
+	verdicts[1].undetermined(CheckTag, errors.New("AccessDeniedException"))
+
+	if err := WriteVerdictsFile(tempFile, verdicts); err != nil {
+		t.Fatalf("WriteVerdictsFile() error = %v", err)
+	}
+
+	content, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+
+	// The log groups of the same name in different regions are told apart by the region column
+	expected := "us-east-1\tlog1\teligible\t\nus-west-2\tlog1\tunknown\ttag: AccessDeniedException\n"
+	if string(content) != expected {
+		t.Errorf("File content = %q, want %q", string(content), expected)
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := map[float64]string{
+		0:          "0.00",
+		12.345:     "12.35",
+		1234.5:     "1,234.50",
+		1234567.89: "1,234,567.89",
+		-1234.5:    "-1,234.50",
+	}
+	for value, expected := range tests {
+		if result := FormatMoney(value); result != expected {
+			t.Errorf("FormatMoney(%v) = %v, want %v", value, result, expected)
+		}
+	}
+}
+
+// TestProgressBar tests the progress bar functionality
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  int
+		total    int
+		expected string
+	}{
+		{
+			name:     "Zero progress",
+			current:  0,
+			total:    10,
+			expected: "[" + strings.Repeat(" ", 50) + "] 0.00% (0/10)",
+		},
+		{
+			name:     "Half progress",
+			current:  5,
+			total:    10,
+			expected: "[" + strings.Repeat("=", 25) + strings.Repeat(" ", 25) + "] 50.00% (5/10)",
+		},
+		{
+			name:     "Complete progress",
+			current:  10,
+			total:    10,
+			expected: "[" + strings.Repeat("=", 50) + "] 100.00% (10/10)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := progressBar(tt.current, tt.total); result != tt.expected {
+				t.Errorf("progressBar(%d, %d) = %q, want %q", tt.current, tt.total, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestWriteToFile tests the file writing functionality
+func TestWriteToFileIntegration(t *testing.T) {
+	// Create a temporary file for testing
+	tempFile := "test_integration_output.txt"
+	defer os.Remove(tempFile) // Clean up after test
+	
+	// Test data
+	testData := []string{"log1", "log2", "log3"}
+	
+	// Write to file
+	err := writeToFile(tempFile, testData)
+	if err != nil {
+		t.Fatalf("writeToFile() error = %v", err)
+	}
+	
+	// Verify file exists
+	_, err = os.Stat(tempFile)
+	if os.IsNotExist(err) {
+		t.Errorf("Expected file %s to exist", tempFile)
+	}
+	
+	// Read file contents
+	content, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	
+	// Verify content
+	expected := "log1\nlog2\nlog3\n"
+	if string(content) != expected {
+		t.Errorf("File content = %v, want %v", string(content), expected)
+	}
+}
@@ -0,0 +1,190 @@
+// This file contains the verdict record that is kept for every log group so the output can say why a group was or was not kept.
+package checker
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// VerdictStatus is the outcome of all checks for a single log group
+type VerdictStatus string
+
+const (
+	StatusEligible   VerdictStatus = "eligible"
+	StatusIneligible VerdictStatus = "ineligible"
+	StatusUnknown    VerdictStatus = "unknown"
+)
+
+// Names of the checks that can exclude a log group
+const (
+	CheckNamePattern        = "name_pattern"
+	CheckMetricFilter       = "metric_filter"
+	CheckDataProtection     = "data_protection"
+	CheckAlreadyIA          = "already_ia"
+	CheckInsights           = "insights"
+	CheckAccountPolicy      = "account_policy"
+	CheckTag                = "tag"
+	CheckFieldIndex         = "field_index"
+	CheckSubscriptionFilter = "subscription_filter"
+	CheckTransformer        = "transformer"
+	CheckAnomalyDetector    = "anomaly_detector"
+	CheckLiveTail           = "live_tail"
+	CheckExportTask         = "export_task"
+	CheckLogEventsRead      = "log_events_read"
+	CheckEMF                = "emf"
+)
+
+// Reason is a single check that fired (or could not be evaluated) for a log group
+type Reason struct {
+	Check  string `json:"check"`
+	Detail string `json:"detail"`
+}
+
+// Verdict holds the log group attributes we report on and the result of every check that was run against it
+type Verdict struct {
+	Account         string
+	Region          string
+	LogGroupName    string
+	LogGroupArn     string
+	LogGroupClass   string
+	RetentionInDays int32
+	StoredBytes     int64
+	CreationTime    int64
+
+	// Estimated ingestion per month, where it was taken from and what moving to IA would save per month in USD
+	MonthlyIngestionBytes float64
+	IngestionSource       string
+	MonthlySavings        float64
+
+	// Checks that were evaluated and did not fire
+	Passed []string
+	// Checks that fired and make the log group ineligible
+	Reasons []Reason
+	// Checks that could not be evaluated, the detail holds the error
+	Unknown []Reason
+	// Informational findings of checks that passed, they do not affect the status
+	Notes []Reason
+	// Most recent calls of Standard-only APIs found in CloudTrail, newest first, at most usagesPerLogGroup
+	Usages []Usage
+	// Every principal that made those calls, with their number of calls
+	Principals []PrincipalUsage
+	// Processors of the transformer of the log group, if it has one
+	Transformer []types.Processor
+
+	// The DescribeLogGroups output the checks are evaluated against
+	logGroup types.LogGroup
+}
+
+func newVerdict(logGroup types.LogGroup) *Verdict {
+	return &Verdict{
+		LogGroupName:    aws.ToString(logGroup.LogGroupName),
+		LogGroupArn:     aws.ToString(logGroup.LogGroupArn),
+		LogGroupClass:   string(logGroup.LogGroupClass),
+		RetentionInDays: aws.ToInt32(logGroup.RetentionInDays),
+		StoredBytes:     aws.ToInt64(logGroup.StoredBytes),
+		CreationTime:    aws.ToInt64(logGroup.CreationTime),
+		logGroup:        logGroup,
+	}
+}
+
+// Status derives the outcome from the recorded checks. Any reason makes the group ineligible,
+// otherwise a check that could not be evaluated leaves it unknown.
+func (v *Verdict) Status() VerdictStatus {
+	if len(v.Reasons) > 0 {
+		return StatusIneligible
+	}
+	if len(v.Unknown) > 0 {
+		return StatusUnknown
+	}
+	return StatusEligible
+}
+
+func (v *Verdict) pass(check string) {
+	v.Passed = append(v.Passed, check)
+}
+
+func (v *Verdict) exclude(check, detail string) {
+	v.Reasons = append(v.Reasons, Reason{Check: check, Detail: detail})
+}
+
+func (v *Verdict) undetermined(check string, err error) {
+	v.Unknown = append(v.Unknown, Reason{Check: check, Detail: err.Error()})
+}
+
+func (v *Verdict) note(check, detail string) {
+	v.Notes = append(v.Notes, Reason{Check: check, Detail: detail})
+}
+
+// Projected savings over a year in USD
+func (v *Verdict) annualSavings() float64 {
+	return v.MonthlySavings * 12
+}
+
+// Result of a single check for tabular output: "pass", "pass: <note>", "fail: <detail>", "unknown: <error>" or empty if
+// it was not evaluated
+func (v *Verdict) checkResult(check string) string {
+	for _, reason := range v.Reasons {
+		if reason.Check == check {
+			return "fail: " + reason.Detail
+		}
+	}
+	for _, reason := range v.Unknown {
+		if reason.Check == check {
+			return "unknown: " + reason.Detail
+		}
+	}
+	for _, note := range v.Notes {
+		if note.Check == check {
+			return "pass: " + note.Detail
+		}
+	}
+	for _, passed := range v.Passed {
+		if passed == check {
+			return "pass"
+		}
+	}
+	return ""
+}
+
+// Summary of the reasons for the text output, e.g. "metric_filter: 2 metric filters; live_tail: 1 StartLiveTail event"
+func (v *Verdict) reasonSummary() string {
+	var parts []string
+	for _, reason := range append(v.Reasons, v.Unknown...) {
+		parts = append(parts, reason.Check+": "+reason.Detail)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Return the verdicts that are still in consideration, i.e. no check has excluded them yet.
+// Groups with an unknown check stay in consideration so later checks can still exclude them.
+func inConsideration(verdicts []*Verdict) []*Verdict {
+	var remaining []*Verdict
+	for _, v := range verdicts {
+		if len(v.Reasons) == 0 {
+			remaining = append(remaining, v)
+		}
+	}
+	return remaining
+}
+
+// Return the names of the log groups that passed every check
+func EligibleNames(verdicts []*Verdict) []string {
+	var names []string
+	for _, v := range verdicts {
+		if v.Status() == StatusEligible {
+			names = append(names, v.LogGroupName)
+		}
+	}
+	return names
+}
+
+// Index the verdicts by log group name so checks that return names or ARNs can find the record
+func verdictsByName(verdicts []*Verdict) map[string]*Verdict {
+	byName := make(map[string]*Verdict, len(verdicts))
+	for _, v := range verdicts {
+		byName[v.LogGroupName] = v
+	}
+	return byName
+}
@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+func TestVerdictStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		verdict  func() *Verdict
+		expected VerdictStatus
+	}{
+		{
+			name:     "No checks fired",
+			verdict:  func() *Verdict { v := &Verdict{}; v.pass(CheckMetricFilter); return v },
+			expected: StatusEligible,
+		},
+		{
+			name:     "A check fired",
+			verdict:  func() *Verdict { v := &Verdict{}; v.exclude(CheckLiveTail, "1 StartLiveTail events"); return v },
+			expected: StatusIneligible,
+		},
+		{
+			name: "A check could not be evaluated",
+			verdict: func() *Verdict {
+				v := &Verdict{}
+				v.undetermined(CheckSubscriptionFilter, errors.New("throttled"))
+				return v
+			},
+			expected: StatusUnknown,
+		},
+		{
+			name: "A reason wins over an unknown check",
+			verdict: func() *Verdict {
+				v := &Verdict{}
+				v.undetermined(CheckSubscriptionFilter, errors.New("throttled"))
+				v.exclude(CheckExportTask, "1 CreateExportTask events")
+				return v
+			},
+			expected: StatusIneligible,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.verdict().Status(); result != tt.expected {
+				t.Errorf("Status() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewVerdict(t *testing.T) {
+	logGroup := types.LogGroup{
+		LogGroupName:    aws.String("my-log-group"),
+		LogGroupArn:     aws.String("arn:aws:logs:us-west-2:123456789012:log-group:my-log-group"),
+		LogGroupClass:   types.LogGroupClassStandard,
+		RetentionInDays: aws.Int32(30),
+		StoredBytes:     aws.Int64(1024),
+		CreationTime:    aws.Int64(1700000000000),
+	}
+	verdict := newVerdict(logGroup)
+
+	expected := &Verdict{
+		LogGroupName:    "my-log-group",
+		LogGroupArn:     "arn:aws:logs:us-west-2:123456789012:log-group:my-log-group",
+		LogGroupClass:   "STANDARD",
+		RetentionInDays: 30,
+		StoredBytes:     1024,
+		CreationTime:    1700000000000,
+		logGroup:        logGroup,
+	}
+	if !reflect.DeepEqual(verdict, expected) {
+		t.Errorf("newVerdict() = %+v, want %+v", verdict, expected)
+	}
+}
+
+func TestEligibleNames(t *testing.T) {
+	verdicts := verdictsFromNames("log1", "log2", "log3")
+	verdicts[1].exclude(CheckMetricFilter, "1 metric filters")
+	verdicts[2].undetermined(CheckSubscriptionFilter, errors.New("throttled"))
+
+	if result := EligibleNames(verdicts); !reflect.DeepEqual(result, []string{"log1"}) {
+		t.Errorf("EligibleNames() = %v, want [log1]", result)
+	}
+	if result := inConsideration(verdicts); len(result) != 2 {
+		t.Errorf("inConsideration() returned %d verdicts, want 2", len(result))
+	}
+}
@@ -2,93 +2,373 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/aws-observability/log-ia-checker/checker"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/account"
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
 )
 
 func main() {
 	// Define flags
 	outfilePtr := flag.String("outfile", "ia.txt", "Output file path (default: ia.txt)")
-	
+	verdictsPtr := flag.String("verdicts", "", "Optional file path to write every log group with its status and exclusion reasons")
+	rulesPtr := flag.String("rules", "", "Optional JSON rules file to enable, disable and tune checks and exclude log groups by name or tag")
+	pricingPtr := flag.String("pricing", "", "Optional JSON file overriding the per-region Standard and IA ingestion prices")
+	catalogPtr := flag.String("catalog", "", "Optional JSON file extending the catalog of AWS-managed log group patterns with more prefixes and regexes")
+	topPtr := flag.Int("top", 20, "Number of candidates to list by projected savings (0 to disable)")
+	formatPtr := flag.String("format", checker.FormatText, "Output format: text, json, ndjson, csv, xlsx or html")
+	regionsPtr := flag.String("regions", "", "Comma separated list of regions to scan, e.g. us-east-1,eu-west-1")
+	allRegionsPtr := flag.Bool("all-regions", false, "Scan every region that is enabled for the account")
+	orgPtr := flag.Bool("org", false, "Scan every active account in the AWS Organization by assuming -role-name in it")
+	accountsFilePtr := flag.String("accounts-file", "", "File with one account ID per line to scan by assuming -role-name in each")
+	roleNamePtr := flag.String("role-name", "", "Name of the role to assume in each account in -org or -accounts-file mode")
+	timeoutPtr := flag.Duration("timeout", 0, "Maximum duration of the scan, e.g. 30m (0 for no limit). A partial report is written on timeout")
+	accountConcurrencyPtr := flag.Int("account-concurrency", checker.DefaultAccountConcurrency, "Number of accounts scanned at the same time")
+	maxConcurrencyPtr := flag.Int("max-concurrency", checker.DefaultMaxConcurrency, "Maximum number of concurrent per log group API calls in each account and region")
+	rpsPtr := flag.Float64("rps", checker.DefaultRequestsPerSecond, "Maximum calls per second to each API in each account and region")
+	progressPtr := flag.String("progress", "", "Progress output on stderr: bar, plain or json (default: bar on a terminal, plain otherwise)")
+	quietPtr := flag.Bool("quiet", false, "Do not report the progress of the scan")
+	cloudTrailDirPtr := flag.String("cloudtrail-dir", "", "Read CloudTrail from a local directory of log files synced from the trail bucket instead of LookupEvents, for lookbacks beyond 90 days")
+	cloudTrailLakePtr := flag.String("cloudtrail-lake", "", "Query CloudTrail from the ARN or ID of a CloudTrail Lake event data store instead of LookupEvents, once for every account and region")
+
 	// Custom usage message
 	flag.Usage = func() {
 		log.Printf("Usage: %s [OPTIONS] [REGION]\n", os.Args[0])
-		log.Println("  REGION: AWS region (optional if AWS_REGION environment variable is set)")
+		log.Println("  REGION: AWS region (optional if -regions, -all-regions or the AWS_REGION environment variable is set)")
 		log.Println("Options:")
 		flag.PrintDefaults()
 	}
-	
+
 	// Parse flags
 	flag.Parse()
-	
-	// Get region from remaining args or environment variable
-	var region string
-	if flag.NArg() > 0 {
-		region = flag.Arg(0)
-	} else {
-		region = os.Getenv("AWS_REGION")
-		if region == "" {
-			log.Fatal("Error: No region provided and AWS_REGION environment variable not set")
+
+	// Get regions from remaining args, the -regions flag or environment variable
+	regions, err := resolveRegions(flag.Args(), *regionsPtr, os.Getenv("AWS_REGION"))
+	if err != nil && !*allRegionsPtr {
+		log.Fatalf("Error: %v", err)
+	}
+
+	// Multi-account mode needs a role to assume
+	multiAccount := *orgPtr || *accountsFilePtr != ""
+	if multiAccount && *roleNamePtr == "" {
+		log.Fatal("Error: -role-name is required with -org or -accounts-file")
+	}
+
+	// Validate the engine limits before doing any work
+	if *maxConcurrencyPtr < 1 || *rpsPtr <= 0 {
+		log.Fatal("Error: -max-concurrency must be at least 1 and -rps must be positive")
+	}
+
+	// Report the progress on stderr, redrawing a bar only when it is a terminal
+	progressFormat := *progressPtr
+	if progressFormat == "" {
+		progressFormat = checker.ProgressPlain
+		if checker.IsTerminal(os.Stderr) {
+			progressFormat = checker.ProgressBar
 		}
 	}
-	
-	// Use the outfile from flag
+	if !checker.IsSupportedProgressFormat(progressFormat) {
+		log.Fatalf("Error: unsupported progress format %q", progressFormat)
+	}
+	var progress *checker.Progress
+	if !*quietPtr {
+		progress = checker.NewProgress(os.Stderr, progressFormat)
+		// Log through the progress, so the log lines are not written over the bars
+		log.SetOutput(progress)
+	}
+
+	// Validate the output format before doing any work
+	if !checker.IsSupportedFormat(*formatPtr) {
+		log.Fatalf("Error: unsupported output format %q", *formatPtr)
+	}
+
+	// Load the ingestion prices before doing any work
+	pricing, err := checker.LoadPricing(*pricingPtr)
+	if err != nil {
+		log.Fatalf("Error: unable to load pricing, %v", err)
+	}
+
+	// Load the catalog of AWS-managed log groups before doing any work
+	catalog, err := checker.LoadCatalog(*catalogPtr)
+	if err != nil {
+		log.Fatalf("Error: unable to load catalog, %v", err)
+	}
+
+	// Load the rules before doing any work
+	rules, err := checker.LoadRules(*rulesPtr)
+	if err != nil {
+		log.Fatalf("Error: unable to load rules, %v", err)
+	}
+	if *cloudTrailDirPtr != "" && *cloudTrailLakePtr != "" {
+		log.Fatal("Error: -cloudtrail-dir and -cloudtrail-lake cannot be used together")
+	}
+	if *cloudTrailDirPtr == "" && *cloudTrailLakePtr == "" {
+		if err := rules.ValidateLookback(checker.MaxLookupEventsDays); err != nil {
+			log.Fatalf("Error: unable to load rules, %v", err)
+		}
+	}
+	if info, err := os.Stat(*cloudTrailDirPtr); *cloudTrailDirPtr != "" && (err != nil || !info.IsDir()) {
+		log.Fatalf("Error: -cloudtrail-dir %s is not a directory", *cloudTrailDirPtr)
+	}
+
+	// Use the outfile from flag, defaulting the extension to the output format
 	outfile := *outfilePtr
+	if !isFlagSet("outfile") {
+		outfile = checker.DefaultOutfile(*formatPtr)
+	}
 
-	// Build a log and trail client
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	// Cancel the scan on Ctrl-C or after -timeout and still write what was found so far
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-sigCtx.Done()
+		stop() // A second Ctrl-C exits right away
+	}()
+	ctx := sigCtx
+	if *timeoutPtr > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeoutPtr)
+		defer cancel()
+	}
+
+	runStart := time.Now()
+	options := checker.Options{
+		Rules:             rules,
+		Pricing:           pricing,
+		Catalog:           catalog,
+		Now:               runStart,
+		MaxConcurrency:    *maxConcurrencyPtr,
+		RequestsPerSecond: *rpsPtr,
+		Progress:          progress,
+		TrailStats:        &checker.TrailStats{},
+		TrailArchive:      *cloudTrailDirPtr,
+	}
+
+	// Load the shared config, every region gets its own clients built from a copy of it. The app ID in the user agent
+	// tells the calls of the checks apart from the usage of the log groups in CloudTrail.
+	configOptions := []func(*config.LoadOptions) error{config.WithAppID(checker.AppID)}
+	if len(regions) > 0 {
+		configOptions = append(configOptions, config.WithRegion(regions[0]))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, configOptions...)
 	if err != nil {
 		log.Fatalf("unable to load SDK config, %v", err)
 	}
-	log_client := cloudwatchlogs.NewFromConfig(cfg)
-	cloudtrail_client := cloudtrail.NewFromConfig(cfg)
 
-	// Retrieve list of log groups and perform initial checks
-	log.Println("Retrieving list of log groups and performing initial checks.")
-	logList := getLogList(log_client)
+	// Query the event data store with the credentials of the current account, in the region of its ARN. A bare ID
+	// is looked up in the region of the config, which -all-regions leaves empty when none is set.
+	if *cloudTrailLakePtr != "" {
+		lakeCfg := cfg.Copy()
+		if parsed, err := arn.Parse(*cloudTrailLakePtr); err == nil {
+			lakeCfg.Region = parsed.Region
+		} else if lakeCfg.Region == "" {
+			log.Fatal("Error: -cloudtrail-lake needs the ARN of the event data store when no region is set with -regions or the AWS config")
+		}
+		options.TrailLake, err = checker.NewTrailLake(cloudtrail.NewFromConfig(lakeCfg), *cloudTrailLakePtr)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
 
-	// Progress bar for log group retrieval
-	totalLogs := len(logList)
-	for i := 0; i < totalLogs; i++ {
-		time.Sleep(50 * time.Millisecond) // Simulate processing delay
-		progressBar(i+1, totalLogs, "Retrieving and checking log groups")
+	// Discover the enabled regions if requested
+	if *allRegionsPtr {
+		if cfg.Region == "" {
+			cfg.Region = "us-east-1"
+		}
+		regions, err = checker.ListEnabledRegions(ctx, account.NewFromConfig(cfg))
+		if err != nil {
+			log.Fatalf("unable to list enabled regions, %v", err)
+		}
+		log.Printf("Scanning %d enabled regions", len(regions))
 	}
 
-	// Remove liveTail events
-	log.Println("Checking for and removing logs with LiveTail events")
-	logList = removeLiveTail(logList, cloudtrail_client)
+	// List the accounts of the organization or accounts file
+	var accounts []string
+	if multiAccount {
+		if *accountsFilePtr != "" {
+			accounts, err = checker.ReadAccountsFile(*accountsFilePtr)
+		} else {
+			accounts, err = checker.ListOrganizationAccounts(ctx, organizations.NewFromConfig(cfg))
+		}
+		if err != nil {
+			log.Fatalf("unable to list accounts, %v", err)
+		}
+		log.Printf("Scanning %d accounts", len(accounts))
+	}
 
-	// Progress bar for liveTail event removal
-	totalLogs = len(logList)
-	for i := 0; i < totalLogs; i++ {
-		time.Sleep(50 * time.Millisecond) // Simulate processing delay
-		progressBar(i+1, totalLogs, "Removing LiveTail events")
+	// The text and ndjson reports are written while the scan runs, so only the aggregates of the verdicts are kept.
+	// The other formats need every verdict at the end.
+	summary := checker.NewSummary(*topPtr)
+	var stream *checker.ReportStream
+	if checker.IsStreamingFormat(*formatPtr) {
+		log.Printf("Writing list to: %s", outfile)
+		stream, err = checker.NewReportStream(outfile, *formatPtr, len(accounts) > 1, len(regions) > 1)
+		if err != nil {
+			log.Fatalf("Error: unable to create outfile, %v", err)
+		}
 	}
 
-	// Remove export events
-	log.Println("Checking for and removing logs with export events")
-	logList = removeExport(logList, cloudtrail_client)
+	// Write every verdict with its reasons as soon as it is done if requested
+	var verdictsWriter *checker.VerdictsWriter
+	if *verdictsPtr != "" {
+		log.Printf("Writing verdicts to: %s", *verdictsPtr)
+		verdictsWriter, err = checker.NewVerdictsWriter(*verdictsPtr, len(accounts) > 1, len(regions) > 1)
+		if err != nil {
+			log.Fatalf("Error: unable to create verdicts file, %v", err)
+		}
+	}
+	options.OnVerdict = func(v *checker.Verdict) {
+		summary.Add(v)
+		if stream != nil {
+			// A failed write is returned again by Close
+			stream.Write(v)
+		}
+		if verdictsWriter != nil {
+			if err := verdictsWriter.Write(v); err != nil {
+				log.Printf("error writing verdicts file: %s", err)
+			}
+		}
+	}
 
-	// Progress bar for export event removal
-	totalLogs = len(logList)
-	for i := 0; i < totalLogs; i++ {
-		time.Sleep(50 * time.Millisecond) // Simulate processing delay
-		progressBar(i+1, totalLogs, "Removing export events")
+	// Scan the current account, or every account of the organization or accounts file
+	var verdicts []*checker.Verdict
+	var failures []checker.ScanFailure
+	switch {
+	case multiAccount && stream != nil:
+		failures = checker.StreamAccounts(ctx, cfg, accounts, *roleNamePtr, *accountConcurrencyPtr, regions, options)
+	case multiAccount:
+		verdicts, failures = checker.ScanAccounts(ctx, cfg, accounts, *roleNamePtr, *accountConcurrencyPtr, regions, options)
+	case stream != nil:
+		failures = checker.StreamRegions(ctx, cfg, regions, options)
+	default:
+		verdicts, failures = checker.ScanRegions(ctx, cfg, regions, options)
 	}
 
 	// Output the final count of logs
-	log.Printf("Logs that should be considered for transition to IA: %d \n", len(logList))
-	log.Printf("Writing list to: %s", outfile)
+	log.Printf("Logs that should be considered for transition to IA: %d \n", summary.Eligible())
+	if *topPtr > 0 {
+		checker.LogTopCandidates(summary.TopCandidates(), *topPtr)
+	}
+	log.Printf("Projected savings of all candidates: $%s/month $%s/year", checker.FormatMoney(summary.TotalSavings()), checker.FormatMoney(summary.TotalSavings()*12))
 
-	// Write the log list to the output file
-	err = writeToFile(outfile, logList)
-	if err != nil {
-		log.Printf("error writing to outfile: %s", err)
+	// Write the report to the output file, or complete the one written during the scan
+	report := &checker.Report{Run: summary.RunInfo(regions, runStart, rules), Verdicts: verdicts, Failures: failures}
+	report.Run.MalformedTrailEvents = options.TrailStats.Malformed()
+	report.Run.CatalogVersion = catalog.Version
+	if err := ctx.Err(); err != nil {
+		report.Run.Incomplete = true
+		report.Run.IncompleteReason = incompleteReason(err, *timeoutPtr)
+		log.Printf("Warning: %s, the report is incomplete", report.Run.IncompleteReason)
+	}
+	if report.Run.MalformedTrailEvents > 0 {
+		log.Printf("Warning: %d CloudTrail events could not be parsed and were skipped", report.Run.MalformedTrailEvents)
+	}
+	if report.Run.Undetermined > 0 {
+		log.Printf("Warning: %d log groups could not be fully evaluated, see the undetermined section of the report", report.Run.Undetermined)
+	}
+	if len(report.Failures) > 0 {
+		log.Printf("Warning: %d accounts or regions could not be scanned, see the failures section of the report", len(report.Failures))
+	}
+	var writeErr error
+	if stream != nil {
+		writeErr = stream.Close(report)
+	} else {
+		log.Printf("Writing list to: %s", outfile)
+		writeErr = checker.WriteReport(outfile, *formatPtr, report)
+	}
+	if writeErr != nil {
+		log.Printf("error writing to outfile: %s", writeErr)
+	}
+
+	if verdictsWriter != nil {
+		if err := verdictsWriter.Close(); err != nil {
+			log.Printf("error writing verdicts file: %s", err)
+		}
 	}
+
+	os.Exit(exitCode(report, writeErr))
+}
+
+// Exit codes for a scan that finished but could not evaluate every log group, or could not write its report
+const (
+	exitIncomplete     = 1
+	exitUndetermined   = 2
+	exitScanFailures   = 3
+	exitReportNotSaved = 4
+)
+
+// Pick the exit code: the report is only trustworthy when it was written and every log group of every account and
+// region was fully evaluated
+func exitCode(report *checker.Report, writeErr error) int {
+	switch {
+	case writeErr != nil:
+		return exitReportNotSaved
+	case report.Run.Incomplete:
+		return exitIncomplete
+	case len(report.Failures) > 0:
+		return exitScanFailures
+	case report.Run.Undetermined > 0:
+		return exitUndetermined
+	}
+	return 0
+}
+
+// Describe why the scan stopped early
+func incompleteReason(err error, timeout time.Duration) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "scan timed out after " + timeout.String()
+	}
+	return "scan was interrupted"
+}
+
+// Work out which regions to scan. The -regions flag wins over the positional REGION argument, which wins over AWS_REGION.
+func resolveRegions(args []string, regionsFlag, envRegion string) ([]string, error) {
+	var regions []string
+	switch {
+	case regionsFlag != "":
+		regions = strings.Split(regionsFlag, ",")
+	case len(args) > 0:
+		regions = args[:1]
+	case envRegion != "":
+		regions = []string{envRegion}
+	default:
+		return nil, errors.New("no region provided and AWS_REGION environment variable not set")
+	}
+
+	// Trim and remove duplicates while keeping the order
+	seen := make(map[string]bool)
+	var unique []string
+	for _, region := range regions {
+		region = strings.TrimSpace(region)
+		if region != "" && !seen[region] {
+			seen[region] = true
+			unique = append(unique, region)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, errors.New("no valid region in -regions")
+	}
+	return unique, nil
+}
+
+// Return true if the flag was passed on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
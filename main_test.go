@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/aws-observability/log-ia-checker/checker"
 )
 
 // TestMainFlagParsing tests the flag parsing functionality
@@ -59,75 +65,85 @@ func TestMainFlagParsing(t *testing.T) {
 	}
 }
 
-// TestProgressBar tests the progress bar functionality
-func TestProgressBar(t *testing.T) {
-	// This is a visual function that outputs to stdout
-	// We can test that it doesn't panic with various inputs
-	
+func TestResolveRegions(t *testing.T) {
 	tests := []struct {
-		name    string
-		current int
-		total   int
-		task    string
+		name        string
+		args        []string
+		regionsFlag string
+		envRegion   string
+		expected    []string
+		expectError bool
 	}{
 		{
-			name:    "Zero progress",
-			current: 0,
-			total:   10,
-			task:    "Testing",
+			name:     "Region from argument",
+			args:     []string{"us-west-2"},
+			expected: []string{"us-west-2"},
+		},
+		{
+			name:      "Region from environment variable",
+			envRegion: "us-east-1",
+			expected:  []string{"us-east-1"},
+		},
+		{
+			name:        "Regions flag wins over the argument",
+			args:        []string{"us-west-2"},
+			regionsFlag: "us-east-1, eu-west-1,us-east-1,",
+			expected:    []string{"us-east-1", "eu-west-1"},
 		},
 		{
-			name:    "Half progress",
-			current: 5,
-			total:   10,
-			task:    "Testing",
+			name:        "No region",
+			expectError: true,
 		},
 		{
-			name:    "Complete progress",
-			current: 10,
-			total:   10,
-			task:    "Testing",
+			name:        "Empty regions flag",
+			regionsFlag: ",",
+			expectError: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Just verify it doesn't panic
-			progressBar(tt.current, tt.total, tt.task)
+			result, err := resolveRegions(tt.args, tt.regionsFlag, tt.envRegion)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("resolveRegions() error = %v, expectError %v", err, tt.expectError)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("resolveRegions() = %v, want %v", result, tt.expected)
+			}
 		})
 	}
 }
 
-// TestWriteToFile tests the file writing functionality
-func TestWriteToFileIntegration(t *testing.T) {
-	// Create a temporary file for testing
-	tempFile := "test_integration_output.txt"
-	defer os.Remove(tempFile) // Clean up after test
-	
-	// Test data
-	testData := []string{"log1", "log2", "log3"}
-	
-	// Write to file
-	err := writeToFile(tempFile, testData)
-	if err != nil {
-		t.Fatalf("writeToFile() error = %v", err)
+func TestIncompleteReason(t *testing.T) {
+	if reason := incompleteReason(context.DeadlineExceeded, 30*time.Minute); reason != "scan timed out after 30m0s" {
+		t.Errorf("incompleteReason() = %q, want timed out", reason)
 	}
-	
-	// Verify file exists
-	_, err = os.Stat(tempFile)
-	if os.IsNotExist(err) {
-		t.Errorf("Expected file %s to exist", tempFile)
+	if reason := incompleteReason(context.Canceled, 0); reason != "scan was interrupted" {
+		t.Errorf("incompleteReason() = %q, want interrupted", reason)
 	}
-	
-	// Read file contents
-	content, err := os.ReadFile(tempFile)
-	if err != nil {
-		t.Fatalf("Failed to read test file: %v", err)
+}
+
+func TestExitCode(t *testing.T) {
+	failures := []checker.ScanFailure{{Account: "123456789012", Error: "AccessDenied"}}
+	tests := []struct {
+		name     string
+		run      checker.RunInfo
+		failures []checker.ScanFailure
+		writeErr error
+		want     int
+	}{
+		{name: "Complete", run: checker.RunInfo{}, want: 0},
+		{name: "Undetermined", run: checker.RunInfo{Undetermined: 3}, want: exitUndetermined},
+		{name: "Incomplete", run: checker.RunInfo{Incomplete: true, Undetermined: 3}, want: exitIncomplete},
+		{name: "Account or region failed", run: checker.RunInfo{Undetermined: 3}, failures: failures, want: exitScanFailures},
+		{name: "Report not written", run: checker.RunInfo{Incomplete: true}, failures: failures, writeErr: errors.New("disk full"), want: exitReportNotSaved},
 	}
-	
-	// Verify content
-	expected := "log1\nlog2\nlog3\n"
-	if string(content) != expected {
-		t.Errorf("File content = %v, want %v", string(content), expected)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(&checker.Report{Run: tt.run, Failures: tt.failures}, tt.writeErr); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
 	}
-}
\ No newline at end of file
+}